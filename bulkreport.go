@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bulkReportLimit caps the failures an import keeps for its report; the
+// counts stay exact.
+const bulkReportLimit = 1000
+
+// keepBulkReport stores the report of a finished import with failures, which
+// F in the document list shows until the next one replaces it.
+func (m model) keepBulkReport(job *importJob) model {
+	m.bulkReportTitle = fmt.Sprintf("Import of %s into %s", job.path, job.index)
+	m.bulkReport = renderBulkReport(job.succeeded, job.failed, job.failures)
+	return m
+}
+
+// openBulkReport shows the last import report kept.
+func (m model) openBulkReport() model {
+	if m.bulkReport == "" {
+		m.statusMessage = "No import failures to show"
+		return m
+	}
+	m.detailViewport.SetContent(m.bulkReport)
+	m.detailViewport.GotoTop()
+	m.mode = modeBulkReport
+	return m
+}
+
+func (m model) updateBulkReport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q":
+			m.mode = modeDocs
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.detailViewport, cmd = m.detailViewport.Update(msg)
+	return m, cmd
+}
+
+// renderBulkReport lists one failure per line under the totals. Lines that
+// never reached the cluster, such as invalid JSON, have no id or status.
+func renderBulkReport(succeeded, failed int, failures []BulkFailure) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d succeeded, %d failed\n", succeeded, failed)
+	if failed > len(failures) {
+		fmt.Fprintf(&builder, "Showing the first %d failures.\n", len(failures))
+	}
+	builder.WriteByte('\n')
+
+	idWidth := len("ID")
+	for _, f := range failures {
+		idWidth = max(idWidth, len(f.ID))
+	}
+	fmt.Fprintf(&builder, "%-*s  %-6s  %s\n", idWidth, "ID", "STATUS", "REASON")
+	for _, f := range failures {
+		id, status := f.ID, "-"
+		if f.ID == "" {
+			id = "-"
+		}
+		if f.Status != 0 {
+			status = strconv.Itoa(f.Status)
+		}
+		fmt.Fprintf(&builder, "%-*s  %-6s  %s\n", idWidth, id, status, f.Reason)
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// cardinalityPending marks an estimate that was asked for and has not
+// arrived yet.
+const cardinalityPending = -1
+
+// highCardinality is the distinct value estimate from which a field is
+// flagged as close to unique per document: a poor column, and a terms
+// aggregation over it would only ever show a sliver of its values.
+const highCardinality = 10000
+
+// cardinalityKey identifies a distinct value estimate; the same field can
+// have a very different count under another query.
+type cardinalityKey struct {
+	index string
+	query string
+	field string
+}
+
+type cardinalityMsg struct {
+	key   cardinalityKey
+	value int64
+	err   error
+}
+
+// requestCardinalities asks for the number of distinct values of the
+// aggregatable fields among columns that have no estimate yet. The column
+// picker shows them, which tells a field worth a column (a handful of values)
+// from one that is unique per document.
+func (m *model) requestCardinalities(columns []string) tea.Cmd {
+	query := m.searchQuery(m.currentQuery)
+	var cmds []tea.Cmd
+	for _, field := range columns {
+		if !m.fieldInfo[field].Aggregatable {
+			continue
+		}
+		key := cardinalityKey{index: m.currentIndex, query: m.currentQuery, field: field}
+		if _, ok := m.cardinality[key]; ok {
+			continue
+		}
+		m.cardinality[key] = cardinalityPending
+		cmds = append(cmds, m.track(cardinalityCmd(m.client, key, query)))
+	}
+	return tea.Batch(cmds...)
+}
+
+func cardinalityCmd(client *Client, key cardinalityKey, query Query) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		value, err := client.Cardinality(ctx, key.index, key.field, query)
+		return cardinalityMsg{key: key, value: value, err: err}
+	}
+}
+
+func (m model) handleCardinality(msg cardinalityMsg) model {
+	if msg.err != nil {
+		// The estimate is only a hint; forget it so the next edit asks again.
+		m.client.logf("cardinality of %s: %v", msg.key.field, msg.err)
+		delete(m.cardinality, msg.key)
+		return m
+	}
+	m.cardinality[msg.key] = msg.value
+	return m
+}
+
+// renderCardinalities lists the estimates known for columns, e.g.
+// "Distinct values: level ≈3, host ≈1,204", followed by a warning naming the
+// fields at or above highCardinality.
+func (m model) renderCardinalities(columns []string) string {
+	var parts, high []string
+	for _, field := range columns {
+		value, ok := m.cardinality[cardinalityKey{index: m.currentIndex, query: m.currentQuery, field: field}]
+		switch {
+		case !ok:
+		case value == cardinalityPending:
+			parts = append(parts, field+" …")
+		default:
+			parts = append(parts, fmt.Sprintf("%s ≈%s", field, groupDigits(value)))
+			if value >= highCardinality {
+				high = append(high, field)
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	line := statusStyle.Render("Distinct values: " + strings.Join(parts, ", "))
+	if len(high) > 0 {
+		line += "\n" + yellowStyle.Render(fmt.Sprintf("Nearly unique per document (≥%s distinct values): %s",
+			groupDigits(highCardinality), strings.Join(high, ", ")))
+	}
+	return line
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Profile holds the connection settings for one named cluster in config.json.
+// Any ELASTICSEARCH_* variable that is set overrides the matching value.
+type Profile struct {
+	URL          string `json:"url"`
+	Username     string `json:"username,omitempty"`
+	Password     string `json:"password,omitempty"`
+	APIKey       string `json:"api_key,omitempty"`
+	ServiceToken string `json:"service_token,omitempty"`
+	CACert       string `json:"ca_cert,omitempty"`
+	Insecure     bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+type fileConfig struct {
+	Profiles map[string]Profile `json:"profiles"`
+	// PinnedFields are shown first, in this order, when rendering documents.
+	PinnedFields []string `json:"pinned_fields,omitempty"`
+	// IndexColumns picks the details shown for each index.
+	IndexColumns []string `json:"index_columns,omitempty"`
+}
+
+// configDir returns ~/.config/elastui, honoring XDG_CONFIG_HOME when set.
+func configDir() (string, error) {
+	if base := strings.TrimSpace(os.Getenv("XDG_CONFIG_HOME")); base != "" {
+		return filepath.Join(base, "elastui"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "elastui"), nil
+}
+
+func configPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// loadConfig reads config.json. A missing file yields an empty config.
+func loadConfig() (*fileConfig, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &fileConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg fileConfig
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// loadProfile reads the named profile from config.json.
+func loadProfile(name string) (*Profile, error) {
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("profile %q requested but %s does not exist", name, path)
+	}
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		names := make([]string, 0, len(cfg.Profiles))
+		for n := range cfg.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		available := strings.Join(names, ", ")
+		if available == "" {
+			available = "none"
+		}
+		return nil, fmt.Errorf("profile %q not found in %s (available: %s)", name, path, available)
+	}
+	return &profile, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02 15:04:05 UTC"
+
+// Epoch milliseconds between 2001 and 2100; unmapped numbers outside this
+// range are never taken for dates.
+const (
+	minEpochMillis = 1e12
+	maxEpochMillis = 4102444800000
+)
+
+// dateAnnotator returns the suffix to show after the value at a field path,
+// or "" for none. Array elements share the path of their field.
+type dateAnnotator func(path string, value any) string
+
+// dateAnnotations annotates fields mapped as date or date_nanos, and unmapped
+// numbers that look like epoch milliseconds.
+func dateAnnotations(fields map[string]FieldInfo) dateAnnotator {
+	return func(path string, value any) string {
+		info, mapped := fields[path]
+		if mapped && !hasType(info, "date", "date_nanos") {
+			return ""
+		}
+		switch v := value.(type) {
+		case float64:
+			return epochMillisLabel(v, mapped)
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return ""
+			}
+			return epochMillisLabel(f, mapped)
+		case string:
+			if !mapped {
+				return ""
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return epochMillisLabel(f, true)
+			}
+			// ISO strings are readable as they are; only normalize the ones
+			// carrying a zone offset.
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil || strings.HasSuffix(v, "Z") {
+				return ""
+			}
+			return t.UTC().Format(dateLayout)
+		}
+		return ""
+	}
+}
+
+func epochMillisLabel(millis float64, mapped bool) string {
+	if millis != math.Trunc(millis) {
+		return ""
+	}
+	if !mapped && (millis < minEpochMillis || millis > maxEpochMillis) {
+		return ""
+	}
+	return time.UnixMilli(int64(millis)).UTC().Format(dateLayout)
+}
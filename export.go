@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const exportBatchSize = 1000
+
+// exportJob streams the documents matching a query to an NDJSON file, one
+// _id and _source per line, in a format the importer reads. Each batch is its
+// own command so progress reaches the status line. The job has its own
+// context, which esc cancels outside of prompts.
+type exportJob struct {
+	index   string
+	query   Query
+	path    string
+	ctx     context.Context
+	cancel  context.CancelFunc
+	after   []json.RawMessage
+	total   int64
+	written int
+
+	// mu guards the file and the point in time, which close may reach from
+	// main after the program quits while a batch is still running.
+	mu     sync.Mutex
+	file   *os.File
+	writer *bufio.Writer
+	pit    string
+	closed bool
+}
+
+type exportBatchMsg struct {
+	job  *exportJob
+	done bool
+	err  error
+}
+
+func (m model) openExport() (model, tea.Cmd) {
+	m.exportInput.SetValue(m.currentIndex + ".ndjson")
+	m.exportInput.CursorEnd()
+	m.mode = modeExport
+	return m, m.exportInput.Focus()
+}
+
+func (m model) updateExport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.mode = modeDocs
+			m.exportInput.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			path := expandHome(strings.TrimSpace(m.exportInput.Value()))
+			if path == "" {
+				m.errMessage = "file path required"
+				return m, nil
+			}
+			if m.export != nil {
+				m.errMessage = fmt.Sprintf("already exporting to %s", m.export.path)
+				return m, nil
+			}
+			file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if errors.Is(err, os.ErrExist) {
+				m.errMessage = fmt.Sprintf("%s already exists; choose another file", path)
+				return m, nil
+			}
+			if err != nil {
+				m.errMessage = err.Error()
+				return m, nil
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			job := &exportJob{
+				index:  m.currentIndex,
+				query:  m.searchQuery(m.currentQuery),
+				path:   path,
+				ctx:    ctx,
+				cancel: cancel,
+				file:   file,
+				writer: bufio.NewWriter(file),
+				total:  -1,
+			}
+			m.export = job
+			m.mode = modeDocs
+			m.exportInput.Blur()
+			m.errMessage = ""
+			m.statusMessage = fmt.Sprintf("Exporting %s to %s... (esc to stop)", m.currentIndex, path)
+			return m, m.track(exportBatchCmd(m.client, job))
+		}
+	}
+
+	var cmd tea.Cmd
+	m.exportInput, cmd = m.exportInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) handleExportBatch(msg exportBatchMsg) (tea.Model, tea.Cmd) {
+	job := msg.job
+	if msg.err == nil && !msg.done {
+		m.statusMessage = fmt.Sprintf("Exported %s / %s docs to %s (esc to stop)", groupDigits(int64(job.written)), exportTotal(job), job.path)
+		return m, m.track(exportBatchCmd(m.client, job))
+	}
+
+	m.export = nil
+	job.cancel()
+	pit, flushErr := job.close()
+	var closing tea.Cmd
+	if pit != "" {
+		closing = closePITCmd(m.client, pit)
+	}
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.statusMessage = fmt.Sprintf("Export stopped after %s docs; %s is incomplete", groupDigits(int64(job.written)), job.path)
+	case msg.err != nil:
+		m = m.showError(fmt.Errorf("export stopped after %d docs, %s is incomplete: %w", job.written, job.path, msg.err))
+	case flushErr != nil:
+		m = m.showError(fmt.Errorf("write %s: %w", job.path, flushErr))
+	default:
+		size := "?"
+		if info, err := os.Stat(job.path); err == nil {
+			size = humanBytes(info.Size())
+		}
+		m.statusMessage = fmt.Sprintf("Exported %s docs to %s (%s)", groupDigits(int64(job.written)), job.path, size)
+	}
+	return m, closing
+}
+
+// stopExport cancels the running export, if any, and reports whether there
+// was one. The batch in flight comes back cancelled and finishes the job.
+func (m *model) stopExport() bool {
+	if m.export == nil {
+		return false
+	}
+	m.export.cancel()
+	return true
+}
+
+// close flushes and closes the file once and returns the point in time the
+// caller should close.
+func (job *exportJob) close() (string, error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.closed {
+		return "", nil
+	}
+	job.closed = true
+	err := job.writer.Flush()
+	if closeErr := job.file.Close(); err == nil {
+		err = closeErr
+	}
+	pit := job.pit
+	job.pit = ""
+	return pit, err
+}
+
+func exportTotal(job *exportJob) string {
+	if job.total < 0 {
+		return "?"
+	}
+	return groupDigits(job.total)
+}
+
+// exportBatchCmd writes the next batch. The first one also counts the
+// matching documents and opens the point in time the others page through.
+func exportBatchCmd(client *Client, job *exportJob) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContextFrom(job.ctx)
+		defer cancel()
+		job.mu.Lock()
+		pit := job.pit
+		job.mu.Unlock()
+		if pit == "" {
+			// The total only feeds the progress line, so a failed count is not
+			// worth stopping for.
+			if total, err := client.Count(ctx, job.index, job.query); err == nil {
+				job.total = total
+			}
+			opened, err := client.OpenPIT(ctx, job.index, pitKeepAlive)
+			if err != nil {
+				return exportBatchMsg{job: job, err: err}
+			}
+			pit = opened
+			if !job.setPIT(pit) {
+				closing, done := client.requestContext()
+				defer done()
+				client.ClosePIT(closing, pit)
+				return exportBatchMsg{job: job, err: context.Canceled}
+			}
+		}
+
+		res, err := client.ExportAfter(ctx, pit, pitKeepAlive, job.query, exportBatchSize, job.after)
+		if err != nil {
+			return exportBatchMsg{job: job, err: err}
+		}
+		job.mu.Lock()
+		defer job.mu.Unlock()
+		if job.closed {
+			return exportBatchMsg{job: job, err: context.Canceled}
+		}
+		job.pit = res.PIT
+		var line bytes.Buffer
+		for _, doc := range res.Documents {
+			line.Reset()
+			if err := writeExportLine(&line, doc); err != nil {
+				return exportBatchMsg{job: job, err: err}
+			}
+			if _, err := job.writer.Write(line.Bytes()); err != nil {
+				return exportBatchMsg{job: job, err: err}
+			}
+			job.written++
+		}
+		job.after = res.After
+		return exportBatchMsg{job: job, done: len(res.Documents) < exportBatchSize}
+	}
+}
+
+// setPIT records the point in time opened for job, closing it instead if the
+// job was closed meanwhile.
+func (job *exportJob) setPIT(pit string) bool {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.closed {
+		return false
+	}
+	job.pit = pit
+	return true
+}
+
+// writeExportLine writes doc as {"_id":...,"_source":...} on a single line,
+// which the importer reads back under the same id. Stored sources keep the
+// formatting they were indexed with, which may span several lines.
+func writeExportLine(buf *bytes.Buffer, doc Document) error {
+	id, err := json.Marshal(doc.ID)
+	if err != nil {
+		return err
+	}
+	buf.WriteString(`{"_id":`)
+	buf.Write(id)
+	buf.WriteString(`,"_source":`)
+	if len(doc.Raw) > 0 {
+		if err := json.Compact(buf, doc.Raw); err != nil {
+			return err
+		}
+	} else {
+		raw, err := json.Marshal(doc.Source)
+		if err != nil {
+			return err
+		}
+		buf.Write(raw)
+	}
+	buf.WriteString("}\n")
+	return nil
+}
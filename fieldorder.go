@@ -0,0 +1,151 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// fieldOrder lists the object keys rendered before all others, in this
+// order, at any depth. The rest follow alphabetically, or keep the server's
+// order where it is known. The model owns the pinned list; commands that
+// render documents get a copy of it.
+type fieldOrder []string
+
+func (o fieldOrder) rank(key string) int {
+	if i := slices.Index(o, key); i >= 0 {
+		return i
+	}
+	return len(o)
+}
+
+// sortKeys orders object keys for display: pinned keys first, then the rest
+// alphabetically.
+func (o fieldOrder) sortKeys(keys []string) {
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := o.rank(keys[i]), o.rank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return keys[i] < keys[j]
+	})
+}
+
+// arrange moves the pinned keys of obj to the front and keeps the others in
+// their order.
+func (o fieldOrder) arrange(obj orderedObject) orderedObject {
+	if len(o) == 0 {
+		return obj
+	}
+	arranged := slices.Clone(obj)
+	slices.SortStableFunc(arranged, func(a, b jsonField) int {
+		return cmp.Compare(o.rank(a.key), o.rank(b.key))
+	})
+	return arranged
+}
+
+// writeCompactJSON encodes value like json.Marshal, but with object keys in
+// order.sortKeys order.
+func writeCompactJSON(builder *strings.Builder, value any, order fieldOrder) error {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		if arr, ok := value.([]any); ok {
+			builder.WriteByte('[')
+			for i, item := range arr {
+				if i > 0 {
+					builder.WriteByte(',')
+				}
+				if err := writeCompactJSON(builder, item, order); err != nil {
+					return err
+				}
+			}
+			builder.WriteByte(']')
+			return nil
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		builder.Write(raw)
+		return nil
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	order.sortKeys(keys)
+	builder.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		builder.Write(name)
+		builder.WriteByte(':')
+		if err := writeCompactJSON(builder, obj[key], order); err != nil {
+			return err
+		}
+	}
+	builder.WriteByte('}')
+	return nil
+}
+
+func parseFieldList(value string) []string {
+	var fields []string
+	for _, part := range strings.Split(value, ",") {
+		if field := strings.TrimSpace(part); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func (m model) openPinFields() (model, tea.Cmd) {
+	m.pinInput.SetValue(strings.Join(m.pinnedFields, ", "))
+	m.pinInput.CursorEnd()
+	m.mode = modePinFields
+	return m, m.pinInput.Focus()
+}
+
+func (m model) updatePinFields(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.pinInput.Blur()
+			m.mode = modeDocDetails
+			return m, nil
+		case tea.KeyEnter:
+			m.pinnedFields = parseFieldList(m.pinInput.Value())
+			m.pinInput.Blur()
+			m.mode = modeDocDetails
+
+			// Previews and renders are cached on the items, so redo them.
+			items := m.docList.Items()
+			for i, item := range items {
+				if doc, ok := item.(docItem); ok {
+					items[i] = doc.rendered(m.pinnedFields)
+				}
+			}
+			m.docList.SetItems(items)
+			m.detailDoc = m.detailDoc.rendered(m.pinnedFields)
+			m.detailViewport.SetContent(m.detailContent(m.detailDoc))
+			m.detailViewport.GotoTop()
+			if len(m.pinnedFields) == 0 {
+				m.statusMessage = "Fields in server order"
+			} else {
+				m.statusMessage = fmt.Sprintf("Pinned fields: %s", strings.Join(m.pinnedFields, ", "))
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.pinInput, cmd = m.pinInput.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDocumentViewKeepsServerOrder(t *testing.T) {
+	raw := json.RawMessage(`{"zone":"eu","message":"hi","@timestamp":"2024-01-01","nested":{"b":1,"a":2}}`)
+	doc := Document{ID: "1", Raw: raw}
+	if err := json.Unmarshal(raw, &doc.Source); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		order fieldOrder
+		want  []string
+	}{
+		{"server order", nil, []string{"zone", "message", "@timestamp", "nested", "b", "a"}},
+		{"pinned first", fieldOrder{"@timestamp", "a"}, []string{"@timestamp", "zone", "message", "nested", "a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := newDocItem(doc, tt.order)
+			paths := jsonLinePaths(item.body(), tt.order)
+			lines := strings.Split(item.full, "\n")
+			last := -1
+			for _, key := range tt.want {
+				at := strings.Index(item.full, `"`+key+`"`)
+				if at < last {
+					t.Fatalf("%s is out of order in\n%s", key, item.full)
+				}
+				last = at
+			}
+			for path, line := range paths {
+				key := path[strings.LastIndex(path, ".")+1:]
+				if !strings.Contains(lines[line], `"`+key+`"`) {
+					t.Errorf("path %s points at line %d: %q", path, line, lines[line])
+				}
+			}
+		})
+	}
+}
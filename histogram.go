@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// histogramIntervals are the calendar intervals offered by the histogram view.
+var histogramIntervals = []string{"1m", "1h", "1d"}
+
+type histogramLoadedMsg struct {
+	index    string
+	field    string
+	interval string
+	query    string
+	buckets  []HistogramBucket
+	err      error
+}
+
+// dateFields lists the fields mapped as dates, @timestamp and timestamp first.
+func (m model) dateFields() []string {
+	var fields []string
+	for name, info := range m.fieldInfo {
+		if hasType(info, "date", "date_nanos") {
+			fields = append(fields, name)
+		}
+	}
+	slices.SortFunc(fields, func(a, b string) int {
+		ra, rb := dateFieldRank(a), dateFieldRank(b)
+		if ra != rb {
+			return ra - rb
+		}
+		return strings.Compare(a, b)
+	})
+	return fields
+}
+
+func dateFieldRank(name string) int {
+	switch name {
+	case "@timestamp":
+		return 0
+	case "timestamp":
+		return 1
+	}
+	return 2
+}
+
+func (m model) openHistogram() (tea.Model, tea.Cmd) {
+	fields := m.dateFields()
+	if len(fields) == 0 {
+		m.statusMessage = fmt.Sprintf("No date field in the mapping of %s", m.currentIndex)
+		return m, nil
+	}
+	if !slices.Contains(fields, m.histField) {
+		m.histField = fields[0]
+	}
+	if m.histInterval == "" {
+		m.histInterval = "1h"
+	}
+	m.mode = modeHistogram
+	m.detailViewport.SetContent("Loading histogram...")
+	m.detailViewport.GotoTop()
+	return m.reloadHistogram()
+}
+
+func (m model) reloadHistogram() (tea.Model, tea.Cmd) {
+	m.statusMessage = fmt.Sprintf("Counting %s per %s...", m.histField, m.histInterval)
+	op := m.track(histogramCmd(m.client, m.currentIndex, m.histField, m.histInterval, m.searchQuery(m.currentQuery)))
+	return m, op
+}
+
+func (m model) updateHistogram(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q":
+			m.mode = modeDocs
+			m.statusMessage = fmt.Sprintf("Back to %s", m.currentIndex)
+			return m, nil
+		case "1", "2", "3":
+			m.histInterval = histogramIntervals[keyMsg.String()[0]-'1']
+			return m.reloadHistogram()
+		case "f":
+			fields := m.dateFields()
+			if len(fields) < 2 {
+				m.statusMessage = fmt.Sprintf("%s is the only date field", m.histField)
+				return m, nil
+			}
+			m.histField = fields[(slices.Index(fields, m.histField)+1)%len(fields)]
+			return m.reloadHistogram()
+		case "r":
+			return m.reloadHistogram()
+		case "!":
+			return m.openErrorDetails(), nil
+		}
+	}
+	var cmd tea.Cmd
+	m.detailViewport, cmd = m.detailViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) handleHistogramLoaded(msg histogramLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m.failed(msg.err, retryOp{kind: retryHistogram, index: msg.index}), nil
+	}
+	if m.mode != modeHistogram || msg.index != m.currentIndex || msg.field != m.histField || msg.interval != m.histInterval {
+		return m, nil
+	}
+	var total int64
+	for _, b := range msg.buckets {
+		total += b.Count
+	}
+	m.detailViewport.SetContent(renderHistogram(msg.buckets, msg.interval, m.width))
+	m.detailViewport.GotoTop()
+	m.statusMessage = fmt.Sprintf("%s: %d docs in %d buckets of %s (query: %s)", msg.field, total, len(msg.buckets), msg.interval, emptyPlaceholder(msg.query))
+	return m, nil
+}
+
+func histogramCmd(client *Client, index, field, interval string, query Query) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		buckets, err := client.DateHistogram(ctx, index, field, interval, query)
+		return histogramLoadedMsg{index: index, field: field, interval: interval, query: query.Text, buckets: buckets, err: err}
+	}
+}
+
+// renderHistogram draws one bar per bucket, scaled so the largest count fills
+// the width left after the labels.
+func renderHistogram(buckets []HistogramBucket, interval string, width int) string {
+	if len(buckets) == 0 {
+		return "No documents match the query."
+	}
+	layout := "2006-01-02"
+	switch interval {
+	case "1m":
+		layout = "2006-01-02 15:04"
+	case "1h":
+		layout = "2006-01-02 15:00"
+	}
+
+	var maxCount int64
+	for _, b := range buckets {
+		maxCount = max(maxCount, b.Count)
+	}
+	countWidth := len(fmt.Sprint(maxCount))
+	barWidth := max(width-len(layout)-countWidth-4, 10)
+
+	var builder strings.Builder
+	for _, b := range buckets {
+		bar := 0
+		if maxCount > 0 {
+			bar = int(b.Count * int64(barWidth) / maxCount)
+		}
+		if bar == 0 && b.Count > 0 {
+			bar = 1
+		}
+		fmt.Fprintf(&builder, "%s %*d %s\n", b.Start.Format(layout), countWidth, b.Count, strings.Repeat("█", bar))
+	}
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// QueryLanguage selects how the text typed in the query prompt is turned into
+// an Elasticsearch query.
+type QueryLanguage int
+
+const (
+	// QueryLucene sends the text as a query_string query.
+	QueryLucene QueryLanguage = iota
+	// QueryKQL translates Kibana Query Language into a bool query, falling
+	// back to query_string for expressions the translator does not handle.
+	QueryKQL
+	// QuerySimple matches free text against Query.Fields with multi_match,
+	// so no syntax is needed.
+	QuerySimple
+)
+
+func (l QueryLanguage) String() string {
+	switch l {
+	case QueryKQL:
+		return "KQL"
+	case QuerySimple:
+		return "simple"
+	}
+	return "Lucene"
+}
+
+// parseQueryLanguage is the inverse of String; unknown names mean Lucene.
+func parseQueryLanguage(name string) QueryLanguage {
+	for _, l := range []QueryLanguage{QueryKQL, QuerySimple} {
+		if name == l.String() {
+			return l
+		}
+	}
+	return QueryLucene
+}
+
+// Query is the text of a search together with its language.
+type Query struct {
+	Text     string
+	Language QueryLanguage
+	// Fields are the fields a QuerySimple search looks in, all of them when
+	// empty. Names may carry wildcards and boosts, e.g. "title^2".
+	Fields []string
+}
+
+// TranslateKQL turns the common subset of KQL into query DSL: field:value,
+// quoted phrases, wildcards, field:* (exists), ranges (<, <=, >, >=),
+// and/or/not and parentheses, including grouped values such as
+// status:(200 or 404). Anything else is reported as an error.
+func TranslateKQL(text string) (map[string]any, error) {
+	tokens, err := lexKQL(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &kqlParser{tokens: tokens}
+	query, err := p.parseOr("")
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != kqlEOF {
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+	return query, nil
+}
+
+type kqlKind int
+
+const (
+	kqlEOF kqlKind = iota
+	kqlWord
+	kqlPhrase
+	kqlColon
+	kqlRange
+	kqlOpen
+	kqlClose
+)
+
+type kqlToken struct {
+	kind kqlKind
+	text string
+	// wildcard is set for words with an unescaped * or ?.
+	wildcard bool
+}
+
+func lexKQL(text string) ([]kqlToken, error) {
+	var tokens []kqlToken
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, kqlToken{kind: kqlOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, kqlToken{kind: kqlClose, text: ")"})
+			i++
+		case r == ':':
+			tokens = append(tokens, kqlToken{kind: kqlColon, text: ":"})
+			i++
+		case r == '<' || r == '>':
+			op := string(r)
+			i++
+			if i < len(runes) && runes[i] == '=' {
+				op += "="
+				i++
+			}
+			tokens = append(tokens, kqlToken{kind: kqlRange, text: op})
+		case r == '{' || r == '}':
+			return nil, fmt.Errorf("nested field queries are not supported")
+		case r == '"':
+			var phrase strings.Builder
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				phrase.WriteRune(runes[i])
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated quoted string")
+			}
+			i++
+			tokens = append(tokens, kqlToken{kind: kqlPhrase, text: phrase.String()})
+		default:
+			var word strings.Builder
+			wildcard := false
+			for ; i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`():<>"{}`, runes[i]); i++ {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				} else if runes[i] == '*' || runes[i] == '?' {
+					wildcard = true
+				}
+				word.WriteRune(runes[i])
+			}
+			tokens = append(tokens, kqlToken{kind: kqlWord, text: word.String(), wildcard: wildcard})
+		}
+	}
+	return tokens, nil
+}
+
+type kqlParser struct {
+	tokens []kqlToken
+	pos    int
+}
+
+func (p *kqlParser) peek() kqlToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return kqlToken{kind: kqlEOF}
+}
+
+func (p *kqlParser) next() kqlToken {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return tok
+}
+
+// keyword reports whether the next token is the operator name.
+func (p *kqlParser) keyword(name string) bool {
+	tok := p.peek()
+	return tok.kind == kqlWord && !tok.wildcard && strings.EqualFold(tok.text, name)
+}
+
+// The parse functions take the field of an enclosing field:( ... ) group, in
+// which case bare values apply to that field.
+
+func (p *kqlParser) parseOr(field string) (map[string]any, error) {
+	left, err := p.parseAnd(field)
+	if err != nil {
+		return nil, err
+	}
+	clauses := []any{left}
+	for p.keyword("or") {
+		p.next()
+		right, err := p.parseAnd(field)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, right)
+	}
+	if len(clauses) == 1 {
+		return left, nil
+	}
+	return map[string]any{"bool": map[string]any{"should": clauses, "minimum_should_match": 1}}, nil
+}
+
+func (p *kqlParser) parseAnd(field string) (map[string]any, error) {
+	left, err := p.parseNot(field)
+	if err != nil {
+		return nil, err
+	}
+	clauses := []any{left}
+	for p.keyword("and") {
+		p.next()
+		right, err := p.parseNot(field)
+		if err != nil {
+			return nil, err
+		}
+		clauses = append(clauses, right)
+	}
+	if len(clauses) == 1 {
+		return left, nil
+	}
+	return map[string]any{"bool": map[string]any{"must": clauses}}, nil
+}
+
+func (p *kqlParser) parseNot(field string) (map[string]any, error) {
+	if p.keyword("not") {
+		p.next()
+		inner, err := p.parseNot(field)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"bool": map[string]any{"must_not": []any{inner}}}, nil
+	}
+	return p.parsePrimary(field)
+}
+
+func (p *kqlParser) parsePrimary(field string) (map[string]any, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case kqlOpen:
+		p.next()
+		inner, err := p.parseOr(field)
+		if err != nil {
+			return nil, err
+		}
+		if p.next().kind != kqlClose {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		return inner, nil
+	case kqlWord, kqlPhrase:
+	default:
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+
+	if field == "" && tok.kind == kqlWord && p.pos+1 < len(p.tokens) {
+		switch after := p.tokens[p.pos+1]; after.kind {
+		case kqlColon:
+			p.pos += 2
+			if p.peek().kind == kqlOpen {
+				p.next()
+				inner, err := p.parseOr(tok.text)
+				if err != nil {
+					return nil, err
+				}
+				if p.next().kind != kqlClose {
+					return nil, fmt.Errorf("missing closing parenthesis")
+				}
+				return inner, nil
+			}
+			return p.parseValue(tok.text)
+		case kqlRange:
+			p.pos += 2
+			value := p.next()
+			if value.kind != kqlWord && value.kind != kqlPhrase {
+				return nil, fmt.Errorf("missing value after %s%s", tok.text, after.text)
+			}
+			ops := map[string]string{"<": "lt", "<=": "lte", ">": "gt", ">=": "gte"}
+			return map[string]any{"range": map[string]any{tok.text: map[string]any{ops[after.text]: value.text}}}, nil
+		}
+	}
+	return p.parseValue(field)
+}
+
+// parseValue reads a phrase or a run of words and matches it against field,
+// or against all fields when field is empty.
+func (p *kqlParser) parseValue(field string) (map[string]any, error) {
+	tok := p.next()
+	if tok.kind == kqlPhrase {
+		if field == "" {
+			return map[string]any{"multi_match": map[string]any{"query": tok.text, "type": "phrase", "lenient": true}}, nil
+		}
+		return map[string]any{"match_phrase": map[string]any{field: tok.text}}, nil
+	}
+	if tok.kind != kqlWord {
+		return nil, fmt.Errorf("missing value for %s", field)
+	}
+
+	words := []string{tok.text}
+	wildcard := tok.wildcard
+	for next := p.peek(); next.kind == kqlWord && !p.keyword("and") && !p.keyword("or") && !p.keyword("not"); next = p.peek() {
+		if p.pos+1 < len(p.tokens) && (p.tokens[p.pos+1].kind == kqlColon || p.tokens[p.pos+1].kind == kqlRange) {
+			return nil, fmt.Errorf("missing and/or before %s", next.text)
+		}
+		words = append(words, next.text)
+		wildcard = wildcard || next.wildcard
+		p.next()
+	}
+	value := strings.Join(words, " ")
+
+	switch {
+	case field == "" && wildcard:
+		return map[string]any{"query_string": map[string]any{"query": value}}, nil
+	case field == "":
+		return map[string]any{"multi_match": map[string]any{"query": value, "lenient": true}}, nil
+	case value == "*":
+		return map[string]any{"exists": map[string]any{"field": field}}, nil
+	case wildcard && len(words) == 1:
+		return map[string]any{"wildcard": map[string]any{field: map[string]any{"value": value, "case_insensitive": true}}}, nil
+	case wildcard:
+		return nil, fmt.Errorf("wildcards in multi-word values are not supported")
+	case strings.ContainsAny(field, "*?"):
+		return map[string]any{"multi_match": map[string]any{"query": value, "fields": []string{field}, "lenient": true}}, nil
+	}
+	return map[string]any{"match": map[string]any{field: value}}, nil
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTranslateKQL(t *testing.T) {
+	tests := []struct {
+		name string
+		kql  string
+		want string
+	}{
+		{"field value", `status:200`, `{"match":{"status":"200"}}`},
+		{"multi-word value", `message:disk full`, `{"match":{"message":"disk full"}}`},
+		{"phrase", `message:"disk full"`, `{"match_phrase":{"message":"disk full"}}`},
+		{"free text", `timeout`, `{"multi_match":{"lenient":true,"query":"timeout"}}`},
+		{"free phrase", `"disk full"`, `{"multi_match":{"lenient":true,"query":"disk full","type":"phrase"}}`},
+		{"and", `a:1 and b:2`, `{"bool":{"must":[{"match":{"a":"1"}},{"match":{"b":"2"}}]}}`},
+		{"or", `a:1 or b:2`, `{"bool":{"minimum_should_match":1,"should":[{"match":{"a":"1"}},{"match":{"b":"2"}}]}}`},
+		{"not", `not a:1`, `{"bool":{"must_not":[{"match":{"a":"1"}}]}}`},
+		{"operators are case-insensitive", `a:1 AND NOT b:2`,
+			`{"bool":{"must":[{"match":{"a":"1"}},{"bool":{"must_not":[{"match":{"b":"2"}}]}}]}}`},
+		{"and binds tighter than or", `a:1 or b:2 and c:3`,
+			`{"bool":{"minimum_should_match":1,"should":[{"match":{"a":"1"}},{"bool":{"must":[{"match":{"b":"2"}},{"match":{"c":"3"}}]}}]}}`},
+		{"not binds tighter than and", `not a:1 and b:2`,
+			`{"bool":{"must":[{"bool":{"must_not":[{"match":{"a":"1"}}]}},{"match":{"b":"2"}}]}}`},
+		{"grouping", `(a:1 or b:2) and c:3`,
+			`{"bool":{"must":[{"bool":{"minimum_should_match":1,"should":[{"match":{"a":"1"}},{"match":{"b":"2"}}]}},{"match":{"c":"3"}}]}}`},
+		{"grouped values", `status:(200 or 404)`,
+			`{"bool":{"minimum_should_match":1,"should":[{"match":{"status":"200"}},{"match":{"status":"404"}}]}}`},
+		{"range lt", `bytes<100`, `{"range":{"bytes":{"lt":"100"}}}`},
+		{"range lte", `bytes <= 100`, `{"range":{"bytes":{"lte":"100"}}}`},
+		{"range gt", `bytes>100`, `{"range":{"bytes":{"gt":"100"}}}`},
+		{"range gte quoted", `@timestamp>="2024-01-01"`, `{"range":{"@timestamp":{"gte":"2024-01-01"}}}`},
+		{"wildcard value", `host:web-*`, `{"wildcard":{"host":{"case_insensitive":true,"value":"web-*"}}}`},
+		{"escaped wildcard", `name:a\*b`, `{"match":{"name":"a*b"}}`},
+		{"wildcard field", `http.*:404`, `{"multi_match":{"fields":["http.*"],"lenient":true,"query":"404"}}`},
+		{"free wildcard", `err*`, `{"query_string":{"query":"err*"}}`},
+		{"exists", `user:*`, `{"exists":{"field":"user"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := TranslateKQL(tt.kql)
+			if err != nil {
+				t.Fatalf("TranslateKQL(%q): %v", tt.kql, err)
+			}
+			got, _ := json.Marshal(query)
+			if string(got) != tt.want {
+				t.Errorf("TranslateKQL(%q)\n got  %s\n want %s", tt.kql, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslateKQLFallback(t *testing.T) {
+	for _, kql := range []string{
+		`items:{ name:x }`,
+		`message:"unterminated`,
+		`(a:1 or b:2`,
+		`a:1 b:2`,
+		`message:disk* full`,
+		`bytes>`,
+		`a:1 or`,
+		`)`,
+	} {
+		t.Run(kql, func(t *testing.T) {
+			if query, err := TranslateKQL(kql); err == nil {
+				t.Fatalf("TranslateKQL(%q) = %v, want an error", kql, query)
+			}
+			got, _ := json.Marshal(searchQuery(Query{Text: kql, Language: QueryKQL}))
+			want, _ := json.Marshal(map[string]any{"query_string": map[string]any{"query": kql}})
+			if string(got) != string(want) {
+				t.Errorf("searchQuery(%q) = %s, want the query_string fallback %s", kql, got, want)
+			}
+		})
+	}
+}
+
+func TestKQLFallbackNoteFollowsTheQuerySent(t *testing.T) {
+	const text = `a:1 b:2`
+	if note := kqlFallbackNote(Query{Text: text, Language: QueryKQL}); note == "" {
+		t.Error("no note for a KQL query sent as query_string")
+	}
+	// The same text searched as Lucene, e.g. before the language was switched,
+	// was never meant as KQL.
+	if note := kqlFallbackNote(Query{Text: text, Language: QueryLucene}); note != "" {
+		t.Errorf("note %q for a Lucene query", note)
+	}
+}
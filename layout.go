@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Below this size the views cannot be drawn without garbling.
+const (
+	minTermWidth  = 40
+	minTermHeight = 10
+)
+
+// maxContentWidth caps the width of the views; wider terminals center them.
+// Zero means no limit.
+var maxContentWidth int
+
+// layout holds the sizes derived from the terminal size.
+type layout struct {
+	width        int // content width
+	margin       int // left padding that centers the content
+	listHeight   int // lists leave room for a title or status line
+	detailHeight int // viewports leave room for a title, footer and status
+	inputWidth   int
+	tooSmall     bool
+}
+
+// checkMaxWidth rejects widths the views cannot be drawn in; a cap below the
+// minimum would report a large terminal as too small.
+func checkMaxWidth(maxWidth int) error {
+	if maxWidth < 0 || (maxWidth > 0 && maxWidth < minTermWidth) {
+		return fmt.Errorf("must be 0 (no limit) or at least %d, got %d", minTermWidth, maxWidth)
+	}
+	return nil
+}
+
+func computeLayout(termWidth, termHeight, maxWidth int) layout {
+	if termWidth < minTermWidth || termHeight < minTermHeight {
+		return layout{width: termWidth, tooSmall: true}
+	}
+	l := layout{width: termWidth}
+	if maxWidth > 0 && termWidth > maxWidth {
+		l.width = maxWidth
+		l.margin = (termWidth - maxWidth) / 2
+	}
+	l.listHeight = termHeight - 2
+	l.detailHeight = termHeight - 4
+	l.inputWidth = l.width - 4
+	return l
+}
+
+func (m model) applyLayout() model {
+	l := m.layout
+	if l.tooSmall {
+		return m
+	}
+	m.width = l.width
+	m.indexList.SetSize(l.width, l.listHeight)
+	m.docList.SetSize(l.width, l.listHeight)
+	m.aliasList.SetSize(l.width, l.listHeight)
+	m.repoList.SetSize(l.width, l.listHeight)
+	m.snapshotList.SetSize(l.width, l.listHeight)
+	m.paletteList.SetSize(l.width, l.listHeight)
+	m.docBodyInput.SetWidth(l.inputWidth)
+	m.mgetInput.SetWidth(l.inputWidth)
+	m.queryInput.Width = l.inputWidth
+	m.columnInput.Width = l.inputWidth
+	m.jumpInput.Width = l.inputWidth
+	m.pinInput.Width = l.inputWidth
+	m.routingInput.Width = l.inputWidth
+	m.exportInput.Width = l.inputWidth
+	m.fieldsInput.Width = l.inputWidth
+	m.detailViewport.Width = l.width
+	m.detailViewport.Height = l.detailHeight
+	return m
+}
+
+// frame centers view when the content is narrower than the terminal.
+func (l layout) frame(view string) string {
+	if l.margin == 0 {
+		return view
+	}
+	pad := strings.Repeat(" ", l.margin)
+	return pad + strings.ReplaceAll(view, "\n", "\n"+pad)
+}
+
+func renderTooSmall(width, height int) string {
+	return fmt.Sprintf("Terminal too small (%dx%d).\nResize to at least %dx%d, or press ctrl+c to quit.", width, height, minTermWidth, minTermHeight)
+}
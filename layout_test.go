@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestComputeLayout(t *testing.T) {
+	tests := []struct {
+		name                  string
+		termWidth, termHeight int
+		maxWidth              int
+		want                  layout
+	}{
+		{"too narrow", minTermWidth - 1, 30, 0, layout{width: minTermWidth - 1, tooSmall: true}},
+		{"too short", 100, minTermHeight - 1, 0, layout{width: 100, tooSmall: true}},
+		{"minimum", minTermWidth, minTermHeight, 0, layout{width: 40, listHeight: 8, detailHeight: 6, inputWidth: 36}},
+		{"above minimum", 120, 40, 0, layout{width: 120, listHeight: 38, detailHeight: 36, inputWidth: 116}},
+		{"at max width", 120, 40, 120, layout{width: 120, listHeight: 38, detailHeight: 36, inputWidth: 116}},
+		{"above max width", 201, 40, 120, layout{width: 120, margin: 40, listHeight: 38, detailHeight: 36, inputWidth: 116}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeLayout(tt.termWidth, tt.termHeight, tt.maxWidth); got != tt.want {
+				t.Errorf("computeLayout(%d, %d, %d) = %+v, want %+v", tt.termWidth, tt.termHeight, tt.maxWidth, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMaxWidth(t *testing.T) {
+	tests := []struct {
+		maxWidth int
+		wantErr  bool
+	}{
+		{0, false},
+		{-1, true},
+		{1, true},
+		{minTermWidth - 1, true},
+		{minTermWidth, false},
+		{160, false},
+	}
+	for _, tt := range tests {
+		if err := checkMaxWidth(tt.maxWidth); (err != nil) != tt.wantErr {
+			t.Errorf("checkMaxWidth(%d) = %v, want error %v", tt.maxWidth, err, tt.wantErr)
+		}
+	}
+}
@@ -1,18 +1,30 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
@@ -21,9 +33,49 @@ import (
 )
 
 const (
-	docPageSize = 20
+	docPageSize     = 20
+	importBatchSize = 500
+	liveSearchDelay = 300 * time.Millisecond
+	refreshDelay    = time.Second
+	readOnlyBadge   = "[READ-ONLY]"
 )
 
+// version is set at build time with -ldflags "-X main.version=v1.2.3".
+// Builds without it, such as go install, fall back to the module version.
+var version = "dev"
+
+func init() {
+	if version != "dev" {
+		return
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+}
+
+// largeDocBytes is the _source size from which documents are flagged as
+// large; -large-doc overrides it.
+var largeDocBytes int64 = 100 << 10
+
+type indexSort int
+
+const (
+	sortByName indexSort = iota
+	sortNewest
+	sortOldest
+)
+
+func (s indexSort) String() string {
+	switch s {
+	case sortNewest:
+		return "newest first"
+	case sortOldest:
+		return "oldest first"
+	default:
+		return "name"
+	}
+}
+
 type mode int
 
 const (
@@ -33,42 +85,220 @@ const (
 	modeCreateDoc
 	modeConfirmDelete
 	modeDocDetails
+	modeAuth
+	modeImport
+	modeAliases
+	modeAliasPrompt
+	modeClusterInfo
+	modeError
+	modeMGet
+	modeProfile
+	modeTable
+	modeTableColumns
+	modeRepositories
+	modeSnapshots
+	modeJumpField
+	modeRestorePrompt
+	modeRestoreConfirm
+	modeCurl
+	modePalette
+	modePinFields
+	modeHistogram
+	modeBulkReport
+	modeExport
+	modeSearchFields
 )
 
 type indexItem struct {
-	info IndexInfo
+	info   IndexInfo
+	pinned bool
+}
+
+type aliasItem struct {
+	name    string
+	indices []string
+}
+
+type repoItem string
+
+type snapshotItem struct {
+	info SnapshotInfo
 }
 
 type docItem struct {
 	id      string
+	routing string
 	preview string
 	full    string
+	text    string
+	size    int
+	source  map[string]any
+	// raw is _source as stored, which edits start from so that large
+	// integers and the key order survive; nil when it is not at hand.
+	raw json.RawMessage
+	// partial marks a source rebuilt from stored fields; see Document.Partial.
+	partial bool
+	// ordered is source decoded in the server's key order, for the document
+	// view; nil when the raw _source is not at hand.
+	ordered orderedObject
 }
 
 func (i indexItem) Title() string {
-	return fmt.Sprintf("%s (%d docs)", i.info.Name, i.info.DocsCount)
+	title := fmt.Sprintf("%s (%s docs)", i.info.Name, groupDigits(i.info.DocsCount))
+	if i.pinned {
+		title = pinMark + " " + title
+	}
+	return title
 }
 
 func (i indexItem) Description() string {
-	size := humanBytes(i.info.StoreBytes)
+	return fmt.Sprintf("health=%s %s", i.info.Health, i.details())
+}
+
+// indexColumnNames lists the columns that -index-columns accepts.
+var indexColumnNames = []string{"status", "size", "pri.size", "pri", "rep", "age"}
+
+// indexColumns are the details shown after the health of each index.
+var indexColumns = []string{"status", "size", "pri.size", "pri", "rep", "age"}
+
+// parseIndexColumns validates a column list against indexColumnNames.
+func parseIndexColumns(columns []string) ([]string, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("no index columns given")
+	}
+	for _, column := range columns {
+		if !slices.Contains(indexColumnNames, column) {
+			return nil, fmt.Errorf("unknown index column %q (available: %s)", column, strings.Join(indexColumnNames, ", "))
+		}
+	}
+	return columns, nil
+}
+
+func (i indexItem) details() string {
+	parts := make([]string, 0, len(indexColumns))
+	for _, column := range indexColumns {
+		var value string
+		switch column {
+		case "status":
+			value = i.info.Status
+		case "size":
+			value = displaySize(i.info.StoreBytes, i.info.StoreSize)
+		case "pri.size":
+			value = displaySize(i.info.PriBytes, i.info.PriSize)
+		case "pri":
+			value = strconv.Itoa(i.info.Primaries)
+		case "rep":
+			value = strconv.Itoa(i.info.Replicas)
+		case "age":
+			value = humanAge(i.info.Created)
+		}
+		parts = append(parts, column+"="+value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// displaySize formats bytes, falling back to the raw _cat value.
+func displaySize(bytes int64, raw string) string {
+	size := humanBytes(bytes)
 	if size == "0 B" {
-		size = strings.TrimSpace(i.info.StoreSize)
+		size = strings.TrimSpace(raw)
 		if size == "" {
 			size = "n/a"
 		}
 	}
-	return fmt.Sprintf(
-		"health=%s status=%s size=%s",
-		i.info.Health,
-		i.info.Status,
-		size,
-	)
+	return size
+}
+
+func (i indexItem) status() (string, string, lipgloss.Style) {
+	return "health=", i.info.Health, healthStyle(i.info.Health)
+}
+
+// statusItem is a list item whose description starts with a colored status.
+type statusItem interface {
+	list.Item
+	status() (label, value string, style lipgloss.Style)
+	details() string
+}
+
+// statusDelegate renders items like the default delegate but colors the
+// status value in the description line of statusItems.
+type statusDelegate struct {
+	list.DefaultDelegate
+}
+
+func newStatusDelegate() statusDelegate {
+	return statusDelegate{DefaultDelegate: list.NewDefaultDelegate()}
+}
+
+func (d statusDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	colored, ok := item.(statusItem)
+	if !ok || m.Width() <= 0 || !d.ShowDescription {
+		d.DefaultDelegate.Render(w, m, index, item)
+		return
+	}
+
+	titleOnly := d.DefaultDelegate
+	titleOnly.ShowDescription = false
+	titleOnly.Render(w, m, index, item)
+
+	label, value, valueStyle := colored.status()
+	descStyle := d.Styles.NormalDesc
+	if m.FilterState() == list.Filtering && m.FilterValue() == "" {
+		descStyle = d.Styles.DimmedDesc
+		valueStyle = descStyle.Inline(true)
+	} else if index == m.Index() && m.FilterState() != list.Filtering {
+		descStyle = d.Styles.SelectedDesc
+		valueStyle = valueStyle.Bold(true)
+	}
+	inline := descStyle.Inline(true)
+	desc := inline.Render(label) + valueStyle.Render(value) + inline.Render(" "+colored.details())
+	fmt.Fprintf(w, "\n%s", descStyle.MaxWidth(m.Width()).Render(desc))
 }
 
 func (i indexItem) FilterValue() string {
 	return i.info.Name
 }
 
+func (r repoItem) Title() string       { return string(r) }
+func (r repoItem) Description() string { return "snapshot repository" }
+func (r repoItem) FilterValue() string { return string(r) }
+
+func (s snapshotItem) Title() string {
+	return s.info.Name
+}
+
+func (s snapshotItem) Description() string {
+	return fmt.Sprintf("state=%s %s", s.info.State, s.details())
+}
+
+func (s snapshotItem) status() (string, string, lipgloss.Style) {
+	return "state=", s.info.State, snapshotStateStyle(s.info.State)
+}
+
+func (s snapshotItem) details() string {
+	started := "n/a"
+	if !s.info.StartTime.IsZero() && s.info.StartTime.Unix() > 0 {
+		started = s.info.StartTime.Local().Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("started=%s indices=%d", started, len(s.info.Indices))
+}
+
+func (s snapshotItem) FilterValue() string {
+	return s.info.Name
+}
+
+func (a aliasItem) Title() string {
+	return a.name
+}
+
+func (a aliasItem) Description() string {
+	return "→ " + strings.Join(a.indices, ", ")
+}
+
+func (a aliasItem) FilterValue() string {
+	return a.name
+}
+
 func (doc docItem) Title() string {
 	if doc.id == "" {
 		return "<generated id>"
@@ -77,11 +307,45 @@ func (doc docItem) Title() string {
 }
 
 func (doc docItem) Description() string {
+	if doc.large() {
+		return yellowStyle.Render(fmt.Sprintf("[large %s]", humanBytes(int64(doc.size)))) + " " + doc.preview
+	}
 	return doc.preview
 }
 
+func (doc docItem) large() bool {
+	return largeDocBytes > 0 && int64(doc.size) >= largeDocBytes
+}
+
 func (doc docItem) FilterValue() string {
-	return doc.id + doc.preview
+	return doc.id + " " + doc.text
+}
+
+// substringFilter matches list items that contain term, ignoring case. Only
+// matches inside the title part of the value are highlighted.
+func substringFilter(term string, targets []string) []list.Rank {
+	term = strings.ToLower(term)
+	var ranks []list.Rank
+	for i, target := range targets {
+		lower := strings.ToLower(target)
+		start := strings.Index(lower, term)
+		if start < 0 {
+			continue
+		}
+		titleLen := strings.Index(lower, " ")
+		if titleLen < 0 {
+			titleLen = len(lower)
+		}
+		var matched []int
+		if start+len(term) <= titleLen {
+			first := utf8.RuneCountInString(lower[:start])
+			for j := 0; j < utf8.RuneCountInString(term); j++ {
+				matched = append(matched, first+j)
+			}
+		}
+		ranks = append(ranks, list.Rank{Index: i, MatchedIndexes: matched})
+	}
+	return ranks
 }
 
 type indicesLoadedMsg struct {
@@ -90,26 +354,150 @@ type indicesLoadedMsg struct {
 }
 
 type docsLoadedMsg struct {
-	index  string
-	query  string
-	took   time.Duration
-	items  []list.Item
-	err    error
-	fields []string
+	seq     int
+	index   string
+	query   Query
+	took    time.Duration
+	items   []list.Item
+	err     error
+	fields  []string
+	matched []string
+
+	// Set for "load more" pages read from a point in time.
+	more  bool
+	size  int
+	pit   string
+	after []json.RawMessage
+	last  []json.RawMessage
 }
 
 type docCreatedMsg struct {
-	id  string
-	err error
+	index   string
+	id      string
+	routing string
+	source  map[string]any
+	raw     json.RawMessage
+	err     error
 }
 
 type docDeletedMsg struct {
-	id  string
-	err error
+	index   string
+	id      string
+	routing string
+	err     error
+}
+
+type docUpdatedMsg struct {
+	index   string
+	id      string
+	routing string
+	body    []byte
+	err     error
+}
+
+type editorFinishedMsg struct {
+	id       string
+	routing  string
+	path     string
+	original []byte
+	err      error
+}
+
+type importJob struct {
+	index           string
+	path            string
+	file            *os.File
+	scanner         *bufio.Scanner
+	line            int
+	batch           int
+	succeeded       int
+	failed          int
+	failures        []BulkFailure
+	continueOnError bool
+}
+
+type importBatchMsg struct {
+	job       *importJob
+	succeeded int
+	failures  []BulkFailure
+	done      bool
+	err       error
+}
+
+// stops reports whether the import ends with this batch: the file is
+// exhausted, a request failed, or a document failed while the import is
+// not set to continue on errors.
+func (msg importBatchMsg) stops() bool {
+	return msg.done || msg.err != nil || (len(msg.failures) > 0 && !msg.job.continueOnError)
+}
+
+type aliasesLoadedMsg struct {
+	items []list.Item
+	err   error
+}
+
+type aliasesUpdatedMsg struct {
+	action AliasAction
+	err    error
+}
+
+type clusterInfoLoadedMsg struct {
+	info *ClusterInfo
+	err  error
+}
+
+type serverVersionMsg struct {
+	version string
+	err     error
+}
+
+type mgetLoadedMsg struct {
+	index string
+	ids   []string
+	items []list.Item
+	err   error
+}
+
+type profileLoadedMsg struct {
+	index   string
+	query   string
+	took    time.Duration
+	hits    int
+	profile map[string]any
+	// rawProfile is the profile as returned, rendered in the server's order.
+	rawProfile json.RawMessage
+	err        error
+}
+
+type reposLoadedMsg struct {
+	items []list.Item
+	err   error
+}
+
+type snapshotsLoadedMsg struct {
+	repo  string
+	items []list.Item
+	err   error
+}
+
+type queryDebounceMsg struct {
+	seq int
+}
+
+type refreshDebounceMsg struct {
+	seq int
+}
+
+type indexRefreshedMsg struct {
+	index  string
+	manual bool
+	err    error
 }
 
 type fieldsLoadedMsg struct {
+	index  string
 	fields []string
+	info   []FieldInfo
 	err    error
 }
 
@@ -117,15 +505,24 @@ var (
 	titleStyle    = lipgloss.NewStyle().Bold(true)
 	statusStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
 	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("203"))
-	queryHelp     = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Use Elasticsearch query_string syntax (blank => match_all)")
+	queryHelp     = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Language: Lucene. Use Elasticsearch query_string syntax (blank => match_all)")
 	queryExamples = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(
 		"Examples: status:200, host:api* AND duration:[0 TO 50], (error OR warning) AND service:web",
 	)
-	jsonKeyStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("75"))
-	jsonStringStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
-	jsonNumberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("81"))
-	jsonBoolStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
-	jsonNullStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	kqlHelp     = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Language: KQL (blank => match_all)")
+	kqlExamples = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render(
+		"Examples: status: 200, host: api* and duration >= 50, not level: (error or warning), message: \"disk full\"",
+	)
+	simpleHelp       = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Render("Language: simple. Type words to find in the chosen fields (blank => match_all)")
+	jsonKeyStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("75"))
+	jsonStringStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	jsonNumberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("81"))
+	jsonBoolStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+	jsonNullStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
+	selectedRowStyle = lipgloss.NewStyle().Reverse(true)
+	greenStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
+	yellowStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+	redStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
 )
 
 type model struct {
@@ -133,35 +530,149 @@ type model struct {
 
 	mode          mode
 	ready         bool
+	pendingG      bool
+	width         int
+	height        int
+	layout        layout
 	statusMessage string
 	errMessage    string
+	lastErr       error
+	retry         *retryOp
+	errReturnMode mode
+	errReturnView viewport.Model
+
+	paletteList   list.Model
+	paletteReturn mode
 
 	indexList list.Model
 	docList   list.Model
 
 	currentIndex string
 	currentQuery string
+	pageSize     int
+
+	savedSession  *sessionState
+	resumePending bool
+
+	searchSeq   int
+	pitID       string
+	pageAfter   []json.RawMessage
+	pageEnd     bool
+	loadingMore bool
+	liveSearch  bool
+	liveSeq     int
+	liveChanged bool
+
+	queryLanguage QueryLanguage
+	searchFields  []string
+	pinnedFields  fieldOrder
+	pinnedIndices indexPins
+
+	indexSort indexSort
+
+	spinner  spinner.Model
+	inFlight int
+	opSeq    int
+	cancels  map[int]context.CancelFunc
+	export   *exportJob
+
+	autoRefresh    bool
+	annotateDates  bool
+	refreshSeq     int
+	refreshPending []string
 
 	queryInput      textinput.Model
 	docIDInput      textinput.Model
+	pipelineInput   textinput.Model
+	routingInput    textinput.Model
+	createOnly      bool
 	docBodyInput    textarea.Model
 	createStep      int
 	pendingDelete   docItem
 	detailDoc       docItem
+	detailReturn    mode
 	availableFields []string
+	matchedFields   []string
+	histField       string
+	histInterval    string
+	curlCommand     string
+	bulkReportTitle string
+	bulkReport      string
+	serverVersion   string
+	fieldInfo       map[string]FieldInfo
+	cardinality     map[cardinalityKey]int64
 	detailViewport  viewport.Model
+	detailOffsets   map[string]int
+	jumpInput       textinput.Model
+	pinInput        textinput.Model
+	jumpPaths       map[string]int
+
+	authInputs     []textinput.Model
+	authFocus      int
+	authReturnMode mode
+
+	importInput    textinput.Model
+	exportInput    textinput.Model
+	importContinue bool
+
+	mgetInput textarea.Model
+
+	tableColumns []string
+	columnInput  textinput.Model
+	fieldsInput  textinput.Model
+
+	repoList     list.Model
+	snapshotList list.Model
+	currentRepo  string
+
+	restoreSnapshot  SnapshotInfo
+	restoreInputs    []textinput.Model
+	restoreFocus     int
+	restoreConfirm   textinput.Model
+	restoreTargets   []string
+	restoreConflicts []string
+	restoreOverride  bool
+
+	aliasList   list.Model
+	aliasIndex  string
+	aliasInput  textinput.Model
+	aliasRemove bool
 }
 
-func newModel(client *Client) model {
-	indexList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
-	indexList.Title = "Indices"
+func newModel(client *Client, saved *sessionState, resume bool) model {
+	indexList := list.New([]list.Item{}, newStatusDelegate(), 0, 0)
+	indexList.Title = indicesTitle(model{client: client})
 	indexList.SetShowStatusBar(false)
 	indexList.SetFilteringEnabled(false)
+	indexList.KeyMap.GoToStart.SetKeys("home")
 
 	docList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
 	docList.Title = "Documents"
 	docList.SetShowStatusBar(false)
-	docList.SetFilteringEnabled(false)
+	docList.Filter = substringFilter
+	docList.KeyMap.Filter.SetKeys(";")
+	docList.KeyMap.GoToStart.SetKeys("home")
+
+	aliasList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	aliasList.Title = "Aliases"
+	aliasList.SetShowStatusBar(false)
+	aliasList.SetFilteringEnabled(false)
+	aliasList.KeyMap.GoToStart.SetKeys("home")
+
+	repoList := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	repoList.Title = "Snapshot repositories"
+	repoList.SetShowStatusBar(false)
+	repoList.SetFilteringEnabled(false)
+	repoList.KeyMap.GoToStart.SetKeys("home")
+
+	snapshotList := list.New([]list.Item{}, newStatusDelegate(), 0, 0)
+	snapshotList.Title = "Snapshots"
+	snapshotList.SetShowStatusBar(false)
+	snapshotList.SetFilteringEnabled(false)
+	snapshotList.KeyMap.GoToStart.SetKeys("home")
+
+	aliasInput := textinput.New()
+	aliasInput.Placeholder = "Alias name"
 
 	queryInput := textinput.New()
 	queryInput.Placeholder = "Query string (empty => match_all)"
@@ -169,108 +680,411 @@ func newModel(client *Client) model {
 	docIDInput := textinput.New()
 	docIDInput.Placeholder = "Document ID (leave blank for auto)"
 
+	pipelineInput := textinput.New()
+	pipelineInput.Placeholder = "Pipeline name (leave blank for none)"
+	pipelineInput.SetValue(strings.TrimSpace(os.Getenv("ELASTICSEARCH_DEFAULT_PIPELINE")))
+
+	routingInput := textinput.New()
+	routingInput.Placeholder = "Routing value (leave blank for none)"
+
 	docBody := textarea.New()
 	docBody.SetWidth(60)
 	docBody.SetHeight(10)
 	docBody.Placeholder = `{"field":"value"}`
 	docBody.ShowLineNumbers = false
 
+	mgetInput := textarea.New()
+	mgetInput.SetWidth(60)
+	mgetInput.SetHeight(8)
+	mgetInput.Placeholder = "id-1, id-2\nid-3"
+	mgetInput.ShowLineNumbers = false
+
+	columnInput := textinput.New()
+	columnInput.Placeholder = "field1, field2.nested"
+
+	fieldsInput := textinput.New()
+	fieldsInput.Placeholder = "title^2, message, tags*"
+
+	importInput := textinput.New()
+	importInput.Placeholder = "Path to NDJSON file"
+
+	exportInput := textinput.New()
+	exportInput.Placeholder = "Path of the NDJSON file to write"
+
+	pinInput := textinput.New()
+	pinInput.Placeholder = "@timestamp, level, message"
+
+	jumpInput := textinput.New()
+	jumpInput.Placeholder = "field.path or items[0].name"
+	jumpInput.ShowSuggestions = true
+
 	detailViewport := viewport.New(0, 0)
 	detailViewport.MouseWheelEnabled = false
 
+	authUser := textinput.New()
+	authUser.Placeholder = "Username"
+	authPassword := textinput.New()
+	authPassword.Placeholder = "Password"
+	authPassword.EchoMode = textinput.EchoPassword
+	authPassword.EchoCharacter = '•'
+	authAPIKey := textinput.New()
+	authAPIKey.Placeholder = "API key (overrides username/password)"
+	authAPIKey.EchoMode = textinput.EchoPassword
+	authAPIKey.EchoCharacter = '•'
+	authToken := textinput.New()
+	authToken.Placeholder = "Service token (overrides API key)"
+	authToken.EchoMode = textinput.EchoPassword
+	authToken.EchoCharacter = '•'
+
 	return model{
 		client:         client,
 		mode:           modeIndices,
+		pageSize:       docPageSize,
+		autoRefresh:    true,
+		annotateDates:  true,
+		spinner:        newSpinner(),
+		cancels:        make(map[int]context.CancelFunc),
+		cardinality:    make(map[cardinalityKey]int64),
+		savedSession:   saved,
+		resumePending:  resume && saved != nil,
 		indexList:      indexList,
 		docList:        docList,
 		queryInput:     queryInput,
 		docIDInput:     docIDInput,
+		pipelineInput:  pipelineInput,
+		routingInput:   routingInput,
 		docBodyInput:   docBody,
 		detailViewport: detailViewport,
+		jumpInput:      jumpInput,
+		pinInput:       pinInput,
+		authInputs:     []textinput.Model{authUser, authPassword, authAPIKey, authToken},
+		importInput:    importInput,
+		exportInput:    exportInput,
+		mgetInput:      mgetInput,
+		columnInput:    columnInput,
+		fieldsInput:    fieldsInput,
+		repoList:       repoList,
+		snapshotList:   snapshotList,
+		paletteList:    newPaletteList(),
+		restoreInputs:  newRestoreInputs(),
+		restoreConfirm: textinput.New(),
+		aliasList:      aliasList,
+		aliasInput:     aliasInput,
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return loadIndicesCmd(m.client)
+	return tea.Batch(loadIndicesCmd(m.client), serverVersionCmd(m.client))
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case opDoneMsg:
+		return m.handleOpDone(msg)
+
+	case spinner.TickMsg:
+		if m.inFlight == 0 {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
+	case tea.KeyMsg:
+		if msg.String() == "esc" && !m.acceptsText() && m.stopExport() {
+			m.statusMessage = "Stopping export..."
+			return m, nil
+		}
+		if msg.String() == "esc" && m.mode != modeQuery && m.mode != modePalette && m.cancelOps() {
+			m.statusMessage = "Cancelling..."
+			return m, nil
+		}
+
 	case tea.WindowSizeMsg:
-		h := msg.Height - 2
-		if h < 5 {
-			h = msg.Height
-		}
-		m.indexList.SetSize(msg.Width, h)
-		m.docList.SetSize(msg.Width, h)
-		m.docBodyInput.SetWidth(msg.Width - 4)
-		m.queryInput.Width = msg.Width - 4
-		detailHeight := msg.Height - 4
-		if detailHeight < 3 {
-			detailHeight = msg.Height - 1
-			if detailHeight < 1 {
-				detailHeight = msg.Height
-			}
-		}
-		m.detailViewport.Width = msg.Width
-		m.detailViewport.Height = detailHeight
+		m.height = msg.Height
+		m.layout = computeLayout(msg.Width, msg.Height, maxContentWidth)
+		m = m.applyLayout()
 		m.ready = true
 		return m, nil
 
 	case indicesLoadedMsg:
 		if msg.err != nil {
-			m.errMessage = msg.err.Error()
-			return m, nil
+			return m.failed(msg.err, retryOp{kind: retryIndices}), nil
 		}
-		m.indexList.SetItems(msg.items)
+		m.indexList.SetItems(sortIndexItems(msg.items, m.indexSort, m.pinnedIndices))
 		if len(msg.items) == 0 {
 			m.statusMessage = "No indices found"
 		} else {
 			m.statusMessage = fmt.Sprintf("Loaded %d indices", len(msg.items))
 		}
+		if m.resumePending {
+			m.resumePending = false
+			return m.resumeSession()
+		}
+		if m.savedSession != nil && m.mode == modeIndices {
+			m.statusMessage += fmt.Sprintf(" • S: resume %s", m.savedSession.Index)
+		}
 		return m, nil
 
 	case docsLoadedMsg:
-		if msg.err != nil {
-			m.errMessage = msg.err.Error()
+		if msg.more {
+			return m.handleMoreLoaded(msg)
+		}
+		if msg.seq != m.searchSeq {
 			return m, nil
 		}
+		if msg.err != nil {
+			return m.failed(msg.err, retryOp{kind: retryDocs, index: msg.index, query: msg.query.Text}), nil
+		}
 		if msg.index == m.currentIndex {
+			// Documents may have changed, so remembered positions may not fit.
+			m.detailOffsets = nil
 			m.docList.SetItems(msg.items)
 			m.availableFields = mergeFields(m.availableFields, msg.fields)
+			m.matchedFields = msg.matched
 			if len(msg.items) == 0 {
-				m.statusMessage = fmt.Sprintf("%s: no docs (query: %s)", msg.index, emptyPlaceholder(msg.query))
+				m.statusMessage = fmt.Sprintf("%s: no docs (query: %s)", msg.index, emptyPlaceholder(msg.query.Text))
 			} else {
-				m.statusMessage = fmt.Sprintf("%s: %d docs • %s • query=%s", msg.index, len(msg.items), msg.took, emptyPlaceholder(msg.query))
+				m.statusMessage = fmt.Sprintf("%s: %d docs • %s • query=%s", msg.index, len(msg.items), msg.took, emptyPlaceholder(msg.query.Text))
+			}
+			if note := kqlFallbackNote(msg.query); note != "" {
+				m.statusMessage += " • " + note
+			}
+			if len(msg.matched) > 0 {
+				m.statusMessage += fmt.Sprintf(" • matched fields: %s (H: use as columns)", truncateString(strings.Join(msg.matched, ", "), 80))
 			}
 		}
 		return m, nil
 
 	case fieldsLoadedMsg:
 		if msg.err != nil {
-			m.errMessage = msg.err.Error()
+			return m.failed(msg.err, retryOp{kind: retryFields, index: msg.index}), nil
+		}
+		if msg.index != m.currentIndex {
 			return m, nil
 		}
 		m.availableFields = mergeFields(m.availableFields, msg.fields)
+		if m.fieldInfo == nil {
+			m.fieldInfo = make(map[string]FieldInfo, len(msg.info))
+		}
+		for _, info := range msg.info {
+			m.fieldInfo[info.Name] = info
+		}
 		return m, nil
 
 	case docCreatedMsg:
+		m.mode = modeDocs
 		if msg.err != nil {
-			m.errMessage = msg.err.Error()
-		} else {
-			m.statusMessage = fmt.Sprintf("Document %s indexed", msg.id)
+			return m.showError(msg.err), nil
 		}
-		m.mode = modeDocs
-		return m, tea.Batch(loadDocsCmd(m.client, m.currentIndex, m.currentQuery), loadFieldsCmd(m.client, m.currentIndex))
+		m.statusMessage = fmt.Sprintf("Document %s indexed", msg.id)
+		if msg.index == m.currentIndex {
+			m = m.applyDocCreated(msg)
+		}
+		refresh := m.scheduleRefresh(msg.index)
+		return m, refresh
 
 	case docDeletedMsg:
+		m.mode = modeDocs
+		if msg.err != nil {
+			return m.failed(msg.err, retryOp{kind: retryDelete, index: msg.index, id: msg.id, routing: msg.routing}), nil
+		}
+		m.statusMessage = fmt.Sprintf("Document %s deleted", msg.id)
+		if msg.index == m.currentIndex {
+			m = m.applyDocDeleted(msg)
+		}
+		refresh := m.scheduleRefresh(msg.index)
+		return m, refresh
+
+	case refreshDebounceMsg:
+		if msg.seq != m.refreshSeq {
+			return m, nil
+		}
+		cmds := make([]tea.Cmd, 0, len(m.refreshPending))
+		for _, index := range m.refreshPending {
+			cmds = append(cmds, refreshIndexCmd(m.client, index, false))
+		}
+		m.refreshPending = nil
+		return m, tea.Batch(cmds...)
+
+	case indexRefreshedMsg:
+		if msg.err != nil && msg.manual {
+			return m.failed(msg.err, retryOp{kind: retryRefresh, index: msg.index}), nil
+		}
+		if msg.err != nil {
+			return m.showError(msg.err), nil
+		}
+		if !msg.manual {
+			return m, nil
+		}
+		m.statusMessage = fmt.Sprintf("Refreshed %s", msg.index)
+		op := m.track(loadIndicesCmd(m.client))
+		return m, op
+
+	case editorFinishedMsg:
+		return m.handleEditorFinished(msg)
+
+	case aliasesLoadedMsg:
+		if msg.err != nil {
+			return m.failed(msg.err, retryOp{kind: retryAliases}), nil
+		}
+		m.aliasList.SetItems(msg.items)
+		if len(msg.items) == 0 {
+			m.statusMessage = "No aliases defined"
+		} else {
+			m.statusMessage = fmt.Sprintf("Loaded %d aliases", len(msg.items))
+		}
+		return m, nil
+
+	case aliasesUpdatedMsg:
+		if msg.err != nil {
+			return m.failed(msg.err, retryOp{kind: retryAliasUpdate, action: msg.action}), nil
+		}
+		if msg.action.Remove {
+			m.statusMessage = fmt.Sprintf("Removed %s from alias %s", msg.action.Index, msg.action.Alias)
+		} else {
+			m.statusMessage = fmt.Sprintf("Added %s to alias %s", msg.action.Index, msg.action.Alias)
+		}
+		op := m.track(loadAliasesCmd(m.client))
+		return m, op
+
+	case histogramLoadedMsg:
+		return m.handleHistogramLoaded(msg)
+
+	case pitClosedMsg:
+		if msg.err != nil {
+			m.client.logf("close point in time: %v", msg.err)
+		}
+		return m, nil
+
+	case cardinalityMsg:
+		return m.handleCardinality(msg), nil
+
+	case mgetLoadedMsg:
+		if msg.err != nil {
+			return m.failed(msg.err, retryOp{kind: retryMGet, index: msg.index, ids: msg.ids}), nil
+		}
+		if msg.index != m.currentIndex {
+			return m, nil
+		}
+		m.searchSeq++
+		closing := m.resetPaging()
+		m.docList.SetItems(msg.items)
+		m.docList.Select(0)
+		m.statusMessage = fmt.Sprintf("%d of %d found", len(msg.items), len(msg.ids))
+		if missing := missingIDs(msg.ids, msg.items); len(missing) > 0 {
+			m.statusMessage += fmt.Sprintf(" • missing: %s", truncateString(strings.Join(missing, ", "), 80))
+		}
+		return m, closing
+
+	case profileLoadedMsg:
+		if msg.err != nil {
+			return m.failed(msg.err, retryOp{kind: retryProfile, index: msg.index, query: msg.query}), nil
+		}
+		if m.mode == modeProfile {
+			m.detailViewport.SetContent(renderProfile(msg))
+			m.detailViewport.GotoTop()
+			m.statusMessage = fmt.Sprintf("Profiled %s: %d hits • %s", msg.index, msg.hits, msg.took)
+		}
+		return m, nil
+
+	case reposLoadedMsg:
 		if msg.err != nil {
-			m.errMessage = msg.err.Error()
+			return m.failed(msg.err, retryOp{kind: retryRepos}), nil
+		}
+		m.repoList.SetItems(msg.items)
+		if len(msg.items) == 0 {
+			m.statusMessage = "No snapshot repositories registered"
 		} else {
-			m.statusMessage = fmt.Sprintf("Document %s deleted", msg.id)
+			m.statusMessage = fmt.Sprintf("Loaded %d repositories", len(msg.items))
+		}
+		return m, nil
+
+	case snapshotsLoadedMsg:
+		if msg.err != nil {
+			return m.failed(msg.err, retryOp{kind: retrySnapshots, repo: msg.repo}), nil
+		}
+		if msg.repo != m.currentRepo {
+			return m, nil
+		}
+		m.snapshotList.SetItems(msg.items)
+		m.statusMessage = fmt.Sprintf("%s: %d snapshots", msg.repo, len(msg.items))
+		return m, nil
+
+	case queryDebounceMsg:
+		if msg.seq != m.liveSeq || m.mode != modeQuery {
+			return m, nil
 		}
+		m.liveChanged = true
+		search := m.searchCmd(strings.TrimSpace(m.queryInput.Value()))
+		return m, search
+
+	case serverVersionMsg:
+		if msg.err != nil {
+			// Only the title misses out; the indices report real failures.
+			m.client.logf("server version: %v", msg.err)
+			return m, nil
+		}
+		m.serverVersion = msg.version
+		m.indexList.Title = indicesTitle(m)
+		if warning := VersionMismatch(msg.version); warning != "" {
+			m.client.logf("warning: %s", warning)
+			m.statusMessage = "Warning: " + warning + "; some APIs may not work"
+		}
+		return m, nil
+
+	case clusterInfoLoadedMsg:
+		if msg.err != nil {
+			return m.failed(msg.err, retryOp{kind: retryClusterInfo}), nil
+		}
+		if m.mode == modeClusterInfo {
+			m.detailViewport.SetContent(renderClusterInfo(msg.info))
+			m.statusMessage = fmt.Sprintf("Cluster %s", msg.info.Name)
+		}
+		return m, nil
+
+	case exportBatchMsg:
+		return m.handleExportBatch(msg)
+
+	case importBatchMsg:
+		return m.handleImportBatch(msg)
+
+	case restoreStartedMsg:
+		return m.handleRestoreStarted(msg)
+
+	case restorePollMsg:
+		return m, restoreProgressCmd(m.client, msg)
+
+	case restoreProgressMsg:
+		return m.handleRestoreProgress(msg)
+
+	case docUpdatedMsg:
+		if msg.err != nil {
+			return m.showError(msg.err), nil
+		}
+		m.statusMessage = fmt.Sprintf("Document %s updated", msg.id)
 		m.mode = modeDocs
-		return m, tea.Batch(loadDocsCmd(m.client, m.currentIndex, m.currentQuery), loadFieldsCmd(m.client, m.currentIndex))
+		if msg.index == m.currentIndex {
+			m = m.applyDocUpdated(msg)
+		}
+		refresh := m.scheduleRefresh(msg.index)
+		return m, refresh
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() != "g" {
+		m.pendingG = false
+	}
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "R" && m.retry != nil && m.errMessage != "" && !m.acceptsText() {
+		return m.retryLast()
+	}
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.opensPalette(keyMsg.String()) {
+		return m.openPalette()
+	}
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		if a, blocked := m.blockedAction(keyMsg.String()); blocked {
+			m.statusMessage = fmt.Sprintf("Read-only mode: %s is disabled", a.desc)
+			return m, nil
+		}
 	}
 
 	switch m.mode {
@@ -286,6 +1100,50 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateConfirmDelete(msg)
 	case modeDocDetails:
 		return m.updateDocDetails(msg)
+	case modeAuth:
+		return m.updateAuth(msg)
+	case modeImport:
+		return m.updateImport(msg)
+	case modeExport:
+		return m.updateExport(msg)
+	case modeAliases:
+		return m.updateAliases(msg)
+	case modeAliasPrompt:
+		return m.updateAliasPrompt(msg)
+	case modeClusterInfo:
+		return m.updateClusterInfo(msg)
+	case modeError:
+		return m.updateErrorDetails(msg)
+	case modeMGet:
+		return m.updateMGet(msg)
+	case modeProfile:
+		return m.updateProfile(msg)
+	case modeTable:
+		return m.updateTable(msg)
+	case modeTableColumns:
+		return m.updateTableColumns(msg)
+	case modeSearchFields:
+		return m.updateSearchFields(msg)
+	case modeRepositories:
+		return m.updateRepositories(msg)
+	case modeSnapshots:
+		return m.updateSnapshots(msg)
+	case modeJumpField:
+		return m.updateJumpField(msg)
+	case modeRestorePrompt:
+		return m.updateRestorePrompt(msg)
+	case modeRestoreConfirm:
+		return m.updateRestoreConfirm(msg)
+	case modeCurl:
+		return m.updateCurl(msg)
+	case modePalette:
+		return m.updatePalette(msg)
+	case modePinFields:
+		return m.updatePinFields(msg)
+	case modeHistogram:
+		return m.updateHistogram(msg)
+	case modeBulkReport:
+		return m.updateBulkReport(msg)
 	default:
 		return m, nil
 	}
@@ -301,35 +1159,132 @@ func (m model) updateIndices(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "r":
 			m.statusMessage = "Refreshing indices..."
-			return m, tea.Batch(cmd, loadIndicesCmd(m.client))
-		case "enter":
+			op := m.track(loadIndicesCmd(m.client))
+			return m, tea.Batch(cmd, op)
+		case "*":
+			return m.togglePin(), cmd
+		case "o":
+			m.indexSort = (m.indexSort + 1) % 3
+			m.indexList.SetItems(sortIndexItems(m.indexList.Items(), m.indexSort, m.pinnedIndices))
+			m.statusMessage = fmt.Sprintf("Sorted indices by %s", m.indexSort)
+			return m, cmd
+		case "f":
 			item, ok := m.indexList.SelectedItem().(indexItem)
-			if ok {
-				m.currentIndex = item.info.Name
-				m.currentQuery = ""
-				m.queryInput.SetValue("")
-				m.mode = modeDocs
-				m.availableFields = nil
-				m.statusMessage = fmt.Sprintf("Loading docs for %s...", m.currentIndex)
-				return m, tea.Batch(cmd, loadDocsCmd(m.client, m.currentIndex, m.currentQuery), loadFieldsCmd(m.client, m.currentIndex))
+			if !ok {
+				return m, cmd
 			}
-		}
+			m.statusMessage = fmt.Sprintf("Refreshing %s...", item.info.Name)
+			op := m.track(refreshIndexCmd(m.client, item.info.Name, true))
+			return m, tea.Batch(cmd, op)
+		case "!":
+			return m.openErrorDetails(), cmd
+		case "g":
+			if m.takeG() {
+				m.indexList.Select(0)
+			}
+			return m, cmd
+		case "s":
+			m.mode = modeRepositories
+			m.statusMessage = "Loading snapshot repositories..."
+			op := m.track(loadReposCmd(m.client))
+			return m, tea.Batch(cmd, op)
+		case "C":
+			m.mode = modeClusterInfo
+			m.detailViewport.SetContent("Loading cluster info...")
+			m.detailViewport.GotoTop()
+			m.statusMessage = "Loading cluster info..."
+			op := m.track(loadClusterInfoCmd(m.client))
+			return m, tea.Batch(cmd, op)
+		case "l":
+			m.aliasIndex = ""
+			if item, ok := m.indexList.SelectedItem().(indexItem); ok {
+				m.aliasIndex = item.info.Name
+			}
+			m.mode = modeAliases
+			m.statusMessage = "Loading aliases..."
+			op := m.track(loadAliasesCmd(m.client))
+			return m, tea.Batch(cmd, op)
+		case "S":
+			if m.savedSession == nil {
+				m.statusMessage = "No saved session"
+				return m, cmd
+			}
+			next, resumeCmd := m.resumeSession()
+			return next, tea.Batch(cmd, resumeCmd)
+		case "enter":
+			item, ok := m.indexList.SelectedItem().(indexItem)
+			if ok {
+				next, reload := m.openIndex(item.info.Name, "")
+				return next, tea.Batch(cmd, reload)
+			}
+		}
 	}
 	return m, cmd
 }
 
+func (m model) openIndex(name, query string) (model, tea.Cmd) {
+	m.currentIndex = name
+	m.currentQuery = query
+	m.queryInput.SetValue(query)
+	m.mode = modeDocs
+	m.availableFields = nil
+	m.fieldInfo = nil
+	m.statusMessage = fmt.Sprintf("Loading docs for %s...", m.currentIndex)
+	reload := m.reloadDocsCmd()
+	return m, reload
+}
+
+func (m model) resumeSession() (model, tea.Cmd) {
+	saved := m.savedSession
+	m.savedSession = nil
+	for _, item := range m.indexList.Items() {
+		if idx, ok := item.(indexItem); ok && idx.info.Name == saved.Index {
+			if saved.PageSize > 0 {
+				m.pageSize = saved.PageSize
+			}
+			m.queryLanguage = parseQueryLanguage(saved.Language)
+			m.searchFields = saved.Fields
+			return m.openIndex(saved.Index, saved.Query)
+		}
+	}
+	m.statusMessage = fmt.Sprintf("Saved index %s no longer exists", saved.Index)
+	return m, nil
+}
+
 func (m model) updateDocs(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		filtering := m.docList.FilterState() == list.Filtering
+		clearing := m.docList.FilterState() == list.FilterApplied && keyMsg.String() == "esc"
+		if filtering || clearing {
+			var cmd tea.Cmd
+			m.docList, cmd = m.docList.Update(msg)
+			return m, cmd
+		}
 		switch keyMsg.String() {
 		case "ctrl+c":
 			return m, tea.Quit
 		case "q", "esc":
 			m.mode = modeIndices
 			m.statusMessage = "Back to indices"
+			m.searchSeq++
+			closing := m.resetPaging()
+			return m, closing
+		case "!":
+			return m.openErrorDetails(), nil
+		case "g":
+			if m.takeG() {
+				m.docList.Select(0)
+			}
 			return m, nil
+		case "m":
+			return m.loadMore()
+		case "D":
+			return m.openHistogram()
 		case "r":
 			m.statusMessage = fmt.Sprintf("Refreshing %s", m.currentIndex)
-			return m, tea.Batch(loadDocsCmd(m.client, m.currentIndex, m.currentQuery), loadFieldsCmd(m.client, m.currentIndex))
+			m.client.InvalidateFields(m.currentIndex)
+			reload := m.reloadDocsCmd()
+			return m, reload
 		case "/":
 			m.mode = modeQuery
 			m.queryInput.SetValue(m.currentQuery)
@@ -344,6 +1299,37 @@ func (m model) updateDocs(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.docBodyInput.SetValue("{\n  \"field\": \"value\"\n}")
 			m.docBodyInput.Reset()
 			return m, nil
+		case "P":
+			m.mode = modeProfile
+			m.detailViewport.SetContent("Profiling query...")
+			m.detailViewport.GotoTop()
+			m.statusMessage = fmt.Sprintf("Profiling %s...", m.currentIndex)
+			op := m.trackCancelable(func(ctx context.Context) tea.Cmd {
+				return profileCmd(ctx, m.client, m.currentIndex, m.searchQuery(m.currentQuery))
+			})
+			return m, op
+		case "M":
+			m.mode = modeMGet
+			m.mgetInput.Reset()
+			m.mgetInput.Focus()
+			return m, nil
+		case "I":
+			m.mode = modeImport
+			m.importInput.SetValue("")
+			m.importInput.Focus()
+			return m, nil
+		case "E":
+			return m.openExport()
+		case "F":
+			return m.openBulkReport(), nil
+		case "A":
+			m.autoRefresh = !m.autoRefresh
+			if m.autoRefresh {
+				m.statusMessage = "Auto refresh after create/edit/delete: on"
+			} else {
+				m.statusMessage = "Auto refresh after create/edit/delete: off (f on the index list refreshes)"
+			}
+			return m, nil
 		case "x", "delete":
 			doc, ok := m.docList.SelectedItem().(docItem)
 			if ok {
@@ -355,13 +1341,29 @@ func (m model) updateDocs(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "enter", "v":
 			doc, ok := m.docList.SelectedItem().(docItem)
 			if ok {
-				m.mode = modeDocDetails
-				m.detailDoc = doc
-				m.detailViewport.SetContent(doc.full)
-				m.detailViewport.GotoTop()
-				m.statusMessage = fmt.Sprintf("Viewing %s", displayDocTitle(doc.id))
+				m = m.openDocDetails(doc, modeDocs)
 			}
 			return m, nil
+		case "|":
+			return m.enterTable(), nil
+		case "y":
+			command, err := m.client.SearchCurl(m.currentIndex, m.searchQuery(m.currentQuery), m.pageSize)
+			if err != nil {
+				return m.showError(err), nil
+			}
+			m.curlCommand = command
+			m.mode = modeCurl
+			m.detailViewport.SetContent(command)
+			m.detailViewport.GotoTop()
+			m.statusMessage = copyToClipboard(command)
+			return m, nil
+		case "H":
+			if len(m.matchedFields) == 0 {
+				m.statusMessage = "No matched fields for the current query"
+				return m, nil
+			}
+			m.tableColumns = matchedColumns(m.matchedFields)
+			return m.enterTable(), nil
 		}
 	}
 
@@ -371,6 +1373,7 @@ func (m model) updateDocs(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) updateQueryInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	before := m.queryInput.Value()
 	var cmd tea.Cmd
 	m.queryInput, cmd = m.queryInput.Update(msg)
 
@@ -380,18 +1383,186 @@ func (m model) updateQueryInput(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.currentQuery = strings.TrimSpace(m.queryInput.Value())
 			m.mode = modeDocs
 			m.queryInput.Blur()
+			m.liveChanged = false
 			m.statusMessage = fmt.Sprintf("Searching %s...", m.currentIndex)
-			return m, tea.Batch(cmd, loadDocsCmd(m.client, m.currentIndex, m.currentQuery))
+			search := m.searchCmd(m.currentQuery)
+			return m, tea.Batch(cmd, search)
 		case tea.KeyEsc:
 			m.mode = modeDocs
 			m.queryInput.Blur()
+			if m.liveChanged {
+				m.liveChanged = false
+				search := m.searchCmd(m.currentQuery)
+				return m, search
+			}
 			return m, nil
+		case tea.KeyCtrlT:
+			switch m.queryLanguage {
+			case QueryLucene:
+				m.queryLanguage = QueryKQL
+			case QueryKQL:
+				m.queryLanguage = QuerySimple
+			default:
+				m.queryLanguage = QueryLucene
+			}
+			m.statusMessage = fmt.Sprintf("Query language: %s", m.queryLanguage)
+			return m, cmd
+		case tea.KeyTab:
+			if m.queryLanguage == QuerySimple {
+				return m.openSearchFields()
+			}
+		case tea.KeyCtrlL:
+			m.liveSearch = !m.liveSearch
+			if m.liveSearch {
+				m.statusMessage = "Live search on"
+			} else {
+				m.statusMessage = "Live search off"
+			}
+			return m, cmd
 		}
 	}
 
+	if m.liveSearch && m.queryInput.Value() != before {
+		m.liveSeq++
+		seq := m.liveSeq
+		return m, tea.Batch(cmd, tea.Tick(liveSearchDelay, func(time.Time) tea.Msg {
+			return queryDebounceMsg{seq: seq}
+		}))
+	}
+
 	return m, cmd
 }
 
+// scheduleRefresh queues a refresh of index after refreshDelay, so a burst of
+// writes costs a single refresh. It does nothing when auto refresh is off.
+func (m *model) scheduleRefresh(index string) tea.Cmd {
+	if !m.autoRefresh {
+		return nil
+	}
+	if !slices.Contains(m.refreshPending, index) {
+		m.refreshPending = append(m.refreshPending, index)
+	}
+	m.refreshSeq++
+	seq := m.refreshSeq
+	return tea.Tick(refreshDelay, func(time.Time) tea.Msg {
+		return refreshDebounceMsg{seq: seq}
+	})
+}
+
+func (m model) applyDocCreated(msg docCreatedMsg) model {
+	item := newDocItem(Document{ID: msg.id, Routing: msg.routing, Source: msg.source, Raw: msg.raw}, m.pinnedFields)
+	for i, existing := range m.docList.Items() {
+		if doc, ok := existing.(docItem); ok && doc.id == msg.id {
+			m.docList.SetItem(i, item)
+			return m
+		}
+	}
+
+	selected := m.docList.Index()
+	m.docList.InsertItem(0, item)
+	if len(m.docList.Items()) > 1 {
+		m.docList.Select(selected + 1)
+	}
+	m.adjustDocCount(msg.index, 1)
+
+	return m.addSourceFields(msg.source)
+}
+
+// applyDocUpdated swaps the edited source into the list, so no reload has to
+// wait for the index refresh.
+func (m model) applyDocUpdated(msg docUpdatedMsg) model {
+	doc := Document{ID: msg.id, Routing: msg.routing, Raw: msg.body}
+	if err := json.Unmarshal(msg.body, &doc.Source); err != nil {
+		return m
+	}
+	for i, existing := range m.docList.Items() {
+		if item, ok := existing.(docItem); ok && item.id == msg.id {
+			m.docList.SetItem(i, newDocItem(doc, m.pinnedFields))
+			break
+		}
+	}
+	return m.addSourceFields(doc.Source)
+}
+
+// addSourceFields adds the field paths of source to the known fields.
+func (m model) addSourceFields(source map[string]any) model {
+	fieldSet := make(map[string]struct{})
+	collectFields(source, "", fieldSet)
+	fields := make([]string, 0, len(fieldSet))
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	m.availableFields = mergeFields(m.availableFields, fields)
+	return m
+}
+
+func (m model) applyDocDeleted(msg docDeletedMsg) model {
+	for i, existing := range m.docList.Items() {
+		if doc, ok := existing.(docItem); ok && doc.id == msg.id {
+			m.docList.RemoveItem(i)
+			break
+		}
+	}
+	m.adjustDocCount(msg.index, -1)
+	return m
+}
+
+func (m *model) adjustDocCount(index string, delta int64) {
+	for i, existing := range m.indexList.Items() {
+		item, ok := existing.(indexItem)
+		if !ok || item.info.Name != index {
+			continue
+		}
+		item.info.DocsCount += delta
+		if item.info.DocsCount < 0 {
+			item.info.DocsCount = 0
+		}
+		m.indexList.SetItem(i, item)
+		return
+	}
+}
+
+// takeG records a "g" key press and reports whether it completed a "gg" motion.
+func (m *model) takeG() bool {
+	if m.pendingG {
+		m.pendingG = false
+		return true
+	}
+	m.pendingG = true
+	return false
+}
+
+func (m *model) searchCmd(query string) tea.Cmd {
+	m.searchSeq++
+	closing := m.resetPaging()
+	search := m.trackCancelable(func(ctx context.Context) tea.Cmd {
+		return loadDocsCmd(ctx, m.client, m.currentIndex, m.searchQuery(query), m.pageSize, m.searchSeq, slices.Clone(m.pinnedFields))
+	})
+	return tea.Batch(closing, search)
+}
+
+// searchQuery pairs text with the language selected in the query prompt.
+func (m model) searchQuery(text string) Query {
+	return Query{Text: text, Language: m.queryLanguage, Fields: m.searchFields}
+}
+
+// kqlFallbackNote explains why a KQL query was sent as query_string, if it
+// was. It looks at the query the results came from, not the current language,
+// which may have been switched since.
+func kqlFallbackNote(query Query) string {
+	if query.Language != QueryKQL || query.Text == "" {
+		return ""
+	}
+	if _, err := TranslateKQL(query.Text); err != nil {
+		return fmt.Sprintf("KQL not understood (%v), searched with query_string", err)
+	}
+	return ""
+}
+
+func (m *model) reloadDocsCmd() tea.Cmd {
+	return tea.Batch(m.searchCmd(m.currentQuery), m.track(loadFieldsCmd(m.client, m.currentIndex)))
+}
+
 func (m model) updateCreateDoc(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch keyMsg.Type {
@@ -399,22 +1570,56 @@ func (m model) updateCreateDoc(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.mode = modeDocs
 			return m, nil
 		case tea.KeyEnter:
-			if m.createStep == 0 {
+			switch m.createStep {
+			case 0:
 				m.createStep = 1
+				m.pipelineInput.CursorEnd()
+				return m, m.pipelineInput.Focus()
+			case 1:
+				m.createStep = 2
+				m.pipelineInput.Blur()
+				m.routingInput.CursorEnd()
+				return m, m.routingInput.Focus()
+			case 2:
+				m.createStep = 3
+				m.routingInput.Blur()
 				m.docBodyInput.Focus()
 				return m, nil
 			}
 			body := strings.TrimSpace(m.docBodyInput.Value())
-			id := strings.TrimSpace(m.docIDInput.Value())
+			opts := CreateDocOptions{
+				ID:         strings.TrimSpace(m.docIDInput.Value()),
+				Pipeline:   strings.TrimSpace(m.pipelineInput.Value()),
+				Routing:    strings.TrimSpace(m.routingInput.Value()),
+				CreateOnly: m.createOnly,
+			}
 			m.statusMessage = "Creating document..."
-			return m, tea.Batch(createDocCmd(m.client, m.currentIndex, id, body))
+			if opts.Pipeline != "" {
+				m.statusMessage = fmt.Sprintf("Creating document via pipeline %s...", opts.Pipeline)
+			}
+			op := m.track(createDocCmd(m.client, m.currentIndex, body, opts))
+			return m, op
+		case tea.KeyTab:
+			if m.createStep == 2 {
+				m.createOnly = !m.createOnly
+				return m, nil
+			}
 		}
 	}
 
-	if m.createStep == 0 {
+	switch m.createStep {
+	case 0:
 		var inputCmd tea.Cmd
 		m.docIDInput, inputCmd = m.docIDInput.Update(msg)
 		return m, inputCmd
+	case 1:
+		var inputCmd tea.Cmd
+		m.pipelineInput, inputCmd = m.pipelineInput.Update(msg)
+		return m, inputCmd
+	case 2:
+		var inputCmd tea.Cmd
+		m.routingInput, inputCmd = m.routingInput.Update(msg)
+		return m, inputCmd
 	}
 
 	var bodyCmd tea.Cmd
@@ -422,29 +1627,190 @@ func (m model) updateCreateDoc(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, bodyCmd
 }
 
-func (m model) updateConfirmDelete(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m model) updateClusterInfo(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch strings.ToLower(keyMsg.String()) {
-		case "y":
-			m.mode = modeDocs
-			m.statusMessage = fmt.Sprintf("Deleting %s...", m.pendingDelete.id)
-			return m, deleteDocCmd(m.client, m.currentIndex, m.pendingDelete.id)
-		case "n", "esc", "enter":
-			m.mode = modeDocs
-			m.statusMessage = "Delete canceled"
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q":
+			m.mode = modeIndices
+			m.statusMessage = "Back to indices"
 			return m, nil
+		case "r":
+			m.statusMessage = "Refreshing cluster info..."
+			op := m.track(loadClusterInfoCmd(m.client))
+			return m, op
+		case "!":
+			return m.openErrorDetails(), nil
 		}
 	}
-	return m, nil
+	var cmd tea.Cmd
+	m.detailViewport, cmd = m.detailViewport.Update(msg)
+	return m, cmd
 }
 
-func (m model) updateDocDetails(msg tea.Msg) (tea.Model, tea.Cmd) {
+func (m model) updateRepositories(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
 		switch keyMsg.String() {
-		case "esc", "q", "enter", "v":
+		case "ctrl+c":
+			return m, tea.Quit
+		case "q", "esc":
+			m.mode = modeIndices
+			m.statusMessage = "Back to indices"
+			return m, nil
+		case "r":
+			m.statusMessage = "Refreshing repositories..."
+			op := m.track(loadReposCmd(m.client))
+			return m, op
+		case "!":
+			return m.openErrorDetails(), nil
+		case "g":
+			if m.takeG() {
+				m.repoList.Select(0)
+			}
+			return m, nil
+		case "enter":
+			if repo, ok := m.repoList.SelectedItem().(repoItem); ok {
+				m.currentRepo = string(repo)
+				m.snapshotList.Title = fmt.Sprintf("Snapshots in %s", repo)
+				m.snapshotList.SetItems(nil)
+				m.mode = modeSnapshots
+				m.statusMessage = fmt.Sprintf("Loading snapshots for %s...", repo)
+				op := m.track(loadSnapshotsCmd(m.client, m.currentRepo))
+				return m, op
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.repoList, cmd = m.repoList.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateSnapshots(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "q", "esc":
+			m.mode = modeRepositories
+			m.statusMessage = "Back to repositories"
+			return m, nil
+		case "r":
+			m.statusMessage = fmt.Sprintf("Refreshing snapshots for %s...", m.currentRepo)
+			op := m.track(loadSnapshotsCmd(m.client, m.currentRepo))
+			return m, op
+		case "t":
+			return m.startRestore()
+		case "!":
+			return m.openErrorDetails(), nil
+		case "g":
+			if m.takeG() {
+				m.snapshotList.Select(0)
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.snapshotList, cmd = m.snapshotList.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateAliases(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "q", "esc":
+			m.mode = modeIndices
+			m.statusMessage = "Back to indices"
+			return m, nil
+		case "r":
+			m.statusMessage = "Refreshing aliases..."
+			op := m.track(loadAliasesCmd(m.client))
+			return m, op
+		case "!":
+			return m.openErrorDetails(), nil
+		case "g":
+			if m.takeG() {
+				m.aliasList.Select(0)
+			}
+			return m, nil
+		case "a", "d":
+			if m.aliasIndex == "" {
+				m.errMessage = "select an index before managing aliases"
+				return m, nil
+			}
+			m.aliasRemove = keyMsg.String() == "d"
+			m.aliasInput.SetValue("")
+			if item, ok := m.aliasList.SelectedItem().(aliasItem); ok {
+				m.aliasInput.SetValue(item.name)
+			}
+			m.aliasInput.CursorEnd()
+			m.aliasInput.Focus()
+			m.mode = modeAliasPrompt
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.aliasList, cmd = m.aliasList.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateAliasPrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.mode = modeAliases
+			m.aliasInput.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			alias := strings.TrimSpace(m.aliasInput.Value())
+			if alias == "" {
+				m.errMessage = "alias name required"
+				return m, nil
+			}
+			action := AliasAction{Remove: m.aliasRemove, Index: m.aliasIndex, Alias: alias}
+			m.mode = modeAliases
+			m.aliasInput.Blur()
+			m.errMessage = ""
+			m.statusMessage = fmt.Sprintf("Updating alias %s...", alias)
+			op := m.track(updateAliasesCmd(m.client, action))
+			return m, op
+		}
+	}
+
+	var cmd tea.Cmd
+	m.aliasInput, cmd = m.aliasInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateProfile(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q":
 			m.mode = modeDocs
 			m.statusMessage = fmt.Sprintf("Back to %s", m.currentIndex)
 			return m, nil
+		case "r":
+			m.statusMessage = fmt.Sprintf("Profiling %s...", m.currentIndex)
+			op := m.trackCancelable(func(ctx context.Context) tea.Cmd {
+				return profileCmd(ctx, m.client, m.currentIndex, m.searchQuery(m.currentQuery))
+			})
+			return m, op
+		case "g":
+			if m.takeG() {
+				m.detailViewport.GotoTop()
+			}
+			return m, nil
+		case "G":
+			m.detailViewport.GotoBottom()
+			return m, nil
 		}
 	}
 	var cmd tea.Cmd
@@ -452,177 +1818,1311 @@ func (m model) updateDocDetails(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m model) View() string {
-	if !m.ready {
-		return "Loading...\n"
+func (m model) updateMGet(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			m.mode = modeDocs
+			m.mgetInput.Blur()
+			return m, nil
+		case "ctrl+s":
+			ids := parseIDList(m.mgetInput.Value())
+			if len(ids) == 0 {
+				m.errMessage = "at least one id required"
+				return m, nil
+			}
+			m.mode = modeDocs
+			m.mgetInput.Blur()
+			m.errMessage = ""
+			m.statusMessage = fmt.Sprintf("Fetching %d docs...", len(ids))
+			op := m.track(mgetCmd(m.client, m.currentIndex, ids, slices.Clone(m.pinnedFields)))
+			return m, op
+		}
 	}
 
-	var builder strings.Builder
-	switch m.mode {
-	case modeIndices:
-		builder.WriteString(m.indexList.View())
-	case modeDocs:
-		builder.WriteString(titleStyle.Render(fmt.Sprintf("Index: %s | query=%s", m.currentIndex, emptyPlaceholder(m.currentQuery))))
-		builder.WriteRune('\n')
-		builder.WriteString(m.docList.View())
-	case modeQuery:
-		builder.WriteString("Enter search query:\n")
-		builder.WriteString(m.queryInput.View())
-		builder.WriteRune('\n')
-		builder.WriteString(queryHelp)
-		builder.WriteRune('\n')
-		builder.WriteString(queryExamples)
-		if fieldsLine := renderFieldList(m.availableFields); fieldsLine != "" {
-			builder.WriteRune('\n')
-			builder.WriteString(fieldsLine)
+	var cmd tea.Cmd
+	m.mgetInput, cmd = m.mgetInput.Update(msg)
+	return m, cmd
+}
+
+func parseIDList(value string) []string {
+	seen := make(map[string]struct{})
+	var ids []string
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		id := strings.TrimSpace(part)
+		if id == "" {
+			continue
 		}
-	case modeCreateDoc:
-		builder.WriteString(titleStyle.Render("Create Document"))
-		builder.WriteRune('\n')
-		if m.createStep == 0 {
-			builder.WriteString("Document ID (blank => auto):\n")
-			builder.WriteString(m.docIDInput.View())
-		} else {
-			builder.WriteString("Document body (compact JSON):\n")
-			builder.WriteString(m.docBodyInput.View())
-			builder.WriteString("\nPress Enter to submit")
+		if _, ok := seen[id]; ok {
+			continue
 		}
-	case modeConfirmDelete:
-		builder.WriteString(titleStyle.Render("Confirm delete"))
-		builder.WriteRune('\n')
-		builder.WriteString(fmt.Sprintf("Delete document %s? (y/N)", m.pendingDelete.id))
-	case modeDocDetails:
-		builder.WriteString(titleStyle.Render(fmt.Sprintf("Document %s", displayDocTitle(m.detailDoc.id))))
-		builder.WriteRune('\n')
-		builder.WriteString(m.detailViewport.View())
-		builder.WriteString("\n(esc/q/enter to go back)")
+		seen[id] = struct{}{}
+		ids = append(ids, id)
 	}
-
-	builder.WriteRune('\n')
-	builder.WriteString(renderStatus(m))
-	return builder.String()
+	return ids
 }
 
-func renderStatus(m model) string {
-	help := "q:quit r:refresh enter:open /:query n:new doc x:delete"
-	switch m.mode {
-	case modeIndices:
-		help = "enter:open index r:refresh q:quit"
-	case modeDocs:
-		help = "esc:back r:refresh /:query n:new x:delete enter:view q:quit"
-	case modeQuery:
-		help = "enter:run esc:cancel"
-	case modeCreateDoc:
-		if m.createStep == 0 {
-			help = "enter:next esc:cancel"
-		} else {
-			help = "enter:create esc:cancel"
+func missingIDs(ids []string, items []list.Item) []string {
+	found := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		if doc, ok := item.(docItem); ok {
+			found[doc.id] = struct{}{}
 		}
-	case modeConfirmDelete:
-		help = "y:confirm n:cancel"
-	case modeDocDetails:
-		help = "esc/q:back arrows/jk:scroll"
 	}
-
-	var parts []string
-	if m.statusMessage != "" {
-		parts = append(parts, statusStyle.Render(m.statusMessage))
+	var missing []string
+	for _, id := range ids {
+		if _, ok := found[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+func (m model) updateImport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.mode = modeDocs
+			m.importInput.Blur()
+			return m, nil
+		case tea.KeyTab:
+			m.importContinue = !m.importContinue
+			return m, nil
+		case tea.KeyEnter:
+			path := expandHome(strings.TrimSpace(m.importInput.Value()))
+			if path == "" {
+				m.errMessage = "file path required"
+				return m, nil
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				m.errMessage = err.Error()
+				return m, nil
+			}
+			scanner := bufio.NewScanner(file)
+			scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+			job := &importJob{
+				index:           m.currentIndex,
+				path:            path,
+				file:            file,
+				scanner:         scanner,
+				continueOnError: m.importContinue,
+			}
+			m.mode = modeDocs
+			m.importInput.Blur()
+			m.errMessage = ""
+			m.statusMessage = fmt.Sprintf("Importing %s into %s...", path, m.currentIndex)
+			op := m.track(importBatchCmd(m.client, job))
+			return m, op
+		}
+	}
+
+	var cmd tea.Cmd
+	m.importInput, cmd = m.importInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) handleImportBatch(msg importBatchMsg) (tea.Model, tea.Cmd) {
+	job := msg.job
+	job.batch++
+	job.succeeded += msg.succeeded
+	job.failed += len(msg.failures)
+	for _, f := range msg.failures {
+		if len(job.failures) == bulkReportLimit {
+			break
+		}
+		job.failures = append(job.failures, f)
+	}
+
+	stop := msg.stops()
+	if msg.err != nil {
+		m.errMessage = fmt.Sprintf("import batch %d: %v", job.batch, msg.err)
+	} else if len(msg.failures) > 0 {
+		first := msg.failures[0]
+		m.errMessage = fmt.Sprintf("import batch %d: %d failed, first: %s", job.batch, len(msg.failures), first.Reason)
+	}
+
+	if !stop {
+		m.statusMessage = fmt.Sprintf("Import batch %d: %d ok, %d failed (total %d ok, %d failed)", job.batch, msg.succeeded, len(msg.failures), job.succeeded, job.failed)
+		op := m.track(importBatchCmd(m.client, job))
+		return m, op
+	}
+
+	job.file.Close()
+	verb := "Imported"
+	if !msg.done {
+		verb = "Import stopped after"
+	}
+	m.statusMessage = fmt.Sprintf("%s %d docs from %s in %d batches (%d failed)", verb, job.succeeded, job.path, job.batch, job.failed)
+	if job.failed > 0 {
+		m = m.keepBulkReport(job)
+		if m.mode == modeDocs {
+			m = m.openBulkReport()
+		} else {
+			m.statusMessage += "; F in the document list shows the failures"
+		}
+	}
+	if job.index != m.currentIndex {
+		return m, nil
+	}
+	reload := m.reloadDocsCmd()
+	return m, reload
+}
+
+func (m model) updateConfirmDelete(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch strings.ToLower(keyMsg.String()) {
+		case "y":
+			m.mode = modeDocs
+			m.statusMessage = fmt.Sprintf("Deleting %s...", m.pendingDelete.id)
+			op := m.track(deleteDocCmd(m.client, m.currentIndex, m.pendingDelete.id, m.pendingDelete.routing))
+			return m, op
+		case "n", "esc", "enter":
+			m.mode = modeDocs
+			m.statusMessage = "Delete canceled"
+			return m, nil
+		}
+	}
+	return m, nil
+}
+
+func (m model) openDocDetails(doc docItem, returnTo mode) model {
+	m.mode = modeDocDetails
+	m.detailReturn = returnTo
+	m.detailDoc = doc
+	m.detailViewport.SetContent(m.detailContent(doc))
+	m.detailViewport.GotoTop()
+	if offset, ok := m.detailOffsets[doc.id]; ok {
+		m.detailViewport.SetYOffset(offset)
+	}
+	m.statusMessage = fmt.Sprintf("Viewing %s", displayDocTitle(doc.id))
+	return m
+}
+
+// detailContent renders doc for the document view, with date annotations
+// when they are on.
+func (m model) detailContent(doc docItem) string {
+	if !m.annotateDates {
+		return doc.full
+	}
+	return formatAnnotatedJSON(doc.body(), m.pinnedFields, dateAnnotations(m.fieldInfo))
+}
+
+func (m model) updateDocDetails(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc", "q", "enter", "v":
+			if m.detailOffsets == nil {
+				m.detailOffsets = make(map[string]int)
+			}
+			m.detailOffsets[m.detailDoc.id] = m.detailViewport.YOffset
+			m.mode = m.detailReturn
+			m.statusMessage = fmt.Sprintf("Back to %s", m.currentIndex)
+			return m, nil
+		case "!":
+			return m.openErrorDetails(), nil
+		case "g":
+			if m.takeG() {
+				m.detailViewport.GotoTop()
+			}
+			return m, nil
+		case "G":
+			m.detailViewport.GotoBottom()
+			return m, nil
+		case "o":
+			return m.openPinFields()
+		case "T":
+			m.annotateDates = !m.annotateDates
+			offset := m.detailViewport.YOffset
+			m.detailViewport.SetContent(m.detailContent(m.detailDoc))
+			m.detailViewport.SetYOffset(offset)
+			if m.annotateDates {
+				m.statusMessage = "Date annotations on"
+			} else {
+				m.statusMessage = "Date annotations off"
+			}
+			return m, nil
+		case "ctrl+d":
+			m.detailViewport.HalfPageDown()
+			return m, nil
+		case "ctrl+u":
+			m.detailViewport.HalfPageUp()
+			return m, nil
+		case ":":
+			m.jumpPaths = jsonLinePaths(m.detailDoc.body(), m.pinnedFields)
+			suggestions := make([]string, 0, len(m.jumpPaths))
+			for path := range m.jumpPaths {
+				suggestions = append(suggestions, path)
+			}
+			sort.Strings(suggestions)
+			m.jumpInput.SetSuggestions(suggestions)
+			m.jumpInput.SetValue("")
+			m.jumpInput.Focus()
+			m.errMessage = ""
+			m.mode = modeJumpField
+			return m, textinput.Blink
+		case "e":
+			if strings.TrimSpace(m.detailDoc.id) == "" {
+				m.errMessage = "cannot edit a document without an id"
+				return m, nil
+			}
+			if m.detailDoc.partial {
+				m.errMessage = fmt.Sprintf("cannot edit %s: _source is disabled and the fields shown are a partial, flattened copy that would overwrite the document", m.detailDoc.id)
+				return m, nil
+			}
+			if len(m.detailDoc.raw) == 0 {
+				m.errMessage = fmt.Sprintf("cannot edit %s: its stored _source was not returned", m.detailDoc.id)
+				return m, nil
+			}
+			m.errMessage = ""
+			m.statusMessage = fmt.Sprintf("Editing %s in %s...", m.detailDoc.id, editorCommand()[0])
+			return m, openEditorCmd(m.detailDoc)
+		}
+	}
+	var cmd tea.Cmd
+	m.detailViewport, cmd = m.detailViewport.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateJumpField(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc":
+			m.jumpInput.Blur()
+			m.mode = modeDocDetails
+			return m, nil
+		case "enter":
+			path := normalizeFieldPath(m.jumpInput.Value())
+			if path == "" {
+				return m, nil
+			}
+			line, ok := m.jumpPaths[path]
+			if !ok {
+				m.errMessage = fmt.Sprintf("field not found: %s", path)
+				return m, nil
+			}
+			m.jumpInput.Blur()
+			m.errMessage = ""
+			m.mode = modeDocDetails
+			m.detailViewport.SetYOffset(line)
+			m.statusMessage = fmt.Sprintf("Jumped to %s", path)
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.jumpInput, cmd = m.jumpInput.Update(msg)
+	return m, cmd
+}
+
+func (m model) updateCurl(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q":
+			m.mode = modeDocs
+			m.statusMessage = fmt.Sprintf("Back to %s", m.currentIndex)
+			return m, nil
+		case "y":
+			m.statusMessage = copyToClipboard(m.curlCommand)
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.detailViewport, cmd = m.detailViewport.Update(msg)
+	return m, cmd
+}
+
+// copyToClipboard copies text and returns a status line describing the result.
+func copyToClipboard(text string) string {
+	if err := clipboard.WriteAll(text); err != nil {
+		return fmt.Sprintf("Clipboard unavailable (%v); copy the command from the screen", err)
+	}
+	return "Copied curl command to clipboard"
+}
+
+func (m model) showError(err error) model {
+	if errors.Is(err, context.Canceled) {
+		m.statusMessage = "Cancelled"
+		return m
+	}
+	m.client.logf("error: %v", err)
+	m.retry = nil
+	m.errMessage = err.Error()
+	m.lastErr = err
+	if IsAuthError(err) && m.mode != modeAuth {
+		m = m.enterAuth()
+	}
+	return m
+}
+
+func (m model) openErrorDetails() model {
+	if m.errMessage == "" {
+		m.statusMessage = "No error to show"
+		return m
+	}
+	content := m.errMessage
+	if m.lastErr != nil && m.lastErr.Error() == m.errMessage {
+		content = formatErrorDetails(m.lastErr)
+	}
+	m.errReturnMode = m.mode
+	m.errReturnView = m.detailViewport
+	m.mode = modeError
+	m.detailViewport.SetContent(content)
+	m.detailViewport.GotoTop()
+	return m
+}
+
+func (m model) updateErrorDetails(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "q", "enter", "!":
+			return m.closeErrorDetails(), nil
+		case "c":
+			m.errMessage = ""
+			m.lastErr = nil
+			m.retry = nil
+			m = m.closeErrorDetails()
+			m.statusMessage = "Error cleared"
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.detailViewport, cmd = m.detailViewport.Update(msg)
+	return m, cmd
+}
+
+// closeErrorDetails returns to the view the error details were opened from,
+// putting back the detail viewport it was showing (cluster info, histogram,
+// document) at its previous scroll offset.
+func (m model) closeErrorDetails() model {
+	width, height := m.detailViewport.Width, m.detailViewport.Height
+	m.mode = m.errReturnMode
+	m.detailViewport = m.errReturnView
+	m.detailViewport.Width = width
+	m.detailViewport.Height = height
+	m.errReturnView = viewport.Model{}
+	return m
+}
+
+func formatErrorDetails(err error) string {
+	var body string
+	var respErr *ResponseError
+	var authErr *AuthError
+	switch {
+	case errors.As(err, &respErr):
+		body = respErr.Body
+	case errors.As(err, &authErr):
+		body = authErr.Body
+	default:
+		return err.Error()
+	}
+
+	var header string
+	if respErr != nil {
+		header = fmt.Sprintf("%s (HTTP %d)", respErr.Action, respErr.StatusCode)
+		if respErr.Cause != nil {
+			header += "\n" + respErr.Cause.Error()
+		}
+	} else {
+		header = fmt.Sprintf("%s (HTTP %d)", authErr.Error(), authErr.StatusCode)
+	}
+	if formatted, err := formatOrderedJSON([]byte(body)); err == nil {
+		return header + "\n\n" + formatted
+	}
+	return header + "\n\n" + body
+}
+
+// enterAuth opens the credentials prompt, which returns to the current view,
+// or to the index list when no index is open.
+func (m model) enterAuth() model {
+	m.authReturnMode = m.mode
+	if m.currentIndex == "" {
+		m.authReturnMode = modeIndices
+	}
+	m.mode = modeAuth
+	for i := range m.authInputs {
+		m.authInputs[i].SetValue("")
+		m.authInputs[i].Blur()
+	}
+	m.authInputs[0].SetValue(m.client.Credentials().Username)
+	m.authInputs[0].CursorEnd()
+	m.authFocus = 0
+	m.authInputs[0].Focus()
+	return m
+}
+
+func (m model) updateAuth(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.mode = m.authReturnMode
+			m.statusMessage = "Credentials unchanged"
+			return m, nil
+		case "tab", "down", "shift+tab", "up":
+			m.authInputs[m.authFocus].Blur()
+			if keyMsg.String() == "tab" || keyMsg.String() == "down" {
+				m.authFocus = (m.authFocus + 1) % len(m.authInputs)
+			} else {
+				m.authFocus = (m.authFocus + len(m.authInputs) - 1) % len(m.authInputs)
+			}
+			return m, m.authInputs[m.authFocus].Focus()
+		case "enter":
+			client, err := m.client.WithCredentials(Credentials{
+				Username:     strings.TrimSpace(m.authInputs[0].Value()),
+				Password:     m.authInputs[1].Value(),
+				APIKey:       m.authInputs[2].Value(),
+				ServiceToken: m.authInputs[3].Value(),
+			})
+			if err != nil {
+				m.errMessage = err.Error()
+				return m, nil
+			}
+			m.client = client
+			m.errMessage = ""
+			m.mode = m.authReturnMode
+			if m.mode != modeIndices {
+				m.statusMessage = fmt.Sprintf("Reconnecting to %s...", m.currentIndex)
+				reload := m.reloadDocsCmd()
+				return m, reload
+			}
+			m.statusMessage = fmt.Sprintf("Reconnecting (auth: %s)...", m.client.AuthMethod())
+			op := m.track(loadIndicesCmd(m.client))
+			return m, op
+		}
+	}
+
+	var cmd tea.Cmd
+	m.authInputs[m.authFocus], cmd = m.authInputs[m.authFocus].Update(msg)
+	return m, cmd
+}
+
+func (m model) View() string {
+	if !m.ready {
+		return "Loading...\n"
+	}
+	if m.layout.tooSmall {
+		return renderTooSmall(m.layout.width, m.height)
+	}
+
+	var builder strings.Builder
+	switch m.mode {
+	case modeIndices:
+		builder.WriteString(m.indexList.View())
+	case modeDocs:
+		builder.WriteString(titleStyle.Render(docsTitle(m)))
+		builder.WriteRune('\n')
+		builder.WriteString(m.docList.View())
+	case modeQuery:
+		builder.WriteString("Enter search query:\n")
+		builder.WriteString(m.queryInput.View())
+		builder.WriteRune('\n')
+		switch m.queryLanguage {
+		case QueryKQL:
+			builder.WriteString(kqlHelp)
+			builder.WriteRune('\n')
+			builder.WriteString(kqlExamples)
+		case QuerySimple:
+			builder.WriteString(simpleHelp)
+			builder.WriteRune('\n')
+			builder.WriteString(statusStyle.Render("Fields: " + searchFieldsLabel(m.searchFields)))
+		default:
+			builder.WriteString(queryHelp)
+			builder.WriteRune('\n')
+			builder.WriteString(queryExamples)
+		}
+		if fieldsLine := renderFieldList(m.availableFields, m.fieldInfo); fieldsLine != "" {
+			builder.WriteRune('\n')
+			builder.WriteString(fieldsLine)
+		}
+		if m.liveSearch {
+			builder.WriteRune('\n')
+			builder.WriteString(renderLiveResults(m.docList.Items(), m.width, m.height-8))
+		}
+	case modeCreateDoc:
+		builder.WriteString(titleStyle.Render("Create Document"))
+		builder.WriteRune('\n')
+		switch m.createStep {
+		case 0:
+			builder.WriteString("Document ID (blank => auto):\n")
+			builder.WriteString(m.docIDInput.View())
+		case 1:
+			builder.WriteString("Ingest pipeline (blank => none):\n")
+			builder.WriteString(m.pipelineInput.View())
+		case 2:
+			builder.WriteString("Routing (blank => none):\n")
+			builder.WriteString(m.routingInput.View())
+			builder.WriteRune('\n')
+			if m.createOnly {
+				builder.WriteString("op_type: create (fail if the id already exists)")
+			} else {
+				builder.WriteString("op_type: index (overwrite a document with the same id)")
+			}
+		default:
+			builder.WriteString("Document body (compact JSON):\n")
+			builder.WriteString(m.docBodyInput.View())
+			builder.WriteString("\nPress Enter to submit")
+		}
+	case modeConfirmDelete:
+		builder.WriteString(titleStyle.Render("Confirm delete"))
+		builder.WriteRune('\n')
+		builder.WriteString(fmt.Sprintf("Delete document %s from %s? This deletes 1 document. (y/N)", m.pendingDelete.id, m.currentIndex))
+		builder.WriteRune('\n')
+		builder.WriteString(statusStyle.Render(truncateString(m.pendingDelete.text, max(m.width-4, 40))))
+	case modeDocDetails:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("Document %s", displayDocTitle(m.detailDoc.id))))
+		size := statusStyle.Render(fmt.Sprintf(" • _source %s", humanBytes(int64(m.detailDoc.size))))
+		if m.detailDoc.large() {
+			size = yellowStyle.Render(fmt.Sprintf(" • _source %s (large)", humanBytes(int64(m.detailDoc.size))))
+		}
+		builder.WriteString(size)
+		builder.WriteRune('\n')
+		builder.WriteString(m.detailViewport.View())
+		builder.WriteString(fmt.Sprintf("\n(esc/q/enter to go back) • %3.0f%%", m.detailViewport.ScrollPercent()*100))
+	case modeJumpField:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("Jump to field in %s", displayDocTitle(m.detailDoc.id))))
+		builder.WriteRune('\n')
+		builder.WriteString(m.jumpInput.View())
+	case modeCurl:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("curl for %s", m.currentIndex)))
+		builder.WriteRune('\n')
+		builder.WriteString(m.detailViewport.View())
+	case modeError:
+		builder.WriteString(errorStyle.Render("Error details"))
+		builder.WriteRune('\n')
+		builder.WriteString(m.detailViewport.View())
+	case modePalette:
+		builder.WriteString(m.paletteList.View())
+	case modeHistogram:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("%s per %s in %s | query=%s", m.histField, m.histInterval, m.currentIndex, emptyPlaceholder(m.currentQuery))))
+		builder.WriteRune('\n')
+		builder.WriteString(m.detailViewport.View())
+	case modeBulkReport:
+		builder.WriteString(titleStyle.Render(m.bulkReportTitle))
+		builder.WriteRune('\n')
+		builder.WriteString(m.detailViewport.View())
+	case modePinFields:
+		builder.WriteString(titleStyle.Render("Fields to show first"))
+		builder.WriteRune('\n')
+		builder.WriteString(m.pinInput.View())
+	case modeRepositories:
+		builder.WriteString(m.repoList.View())
+	case modeSnapshots:
+		builder.WriteString(m.snapshotList.View())
+	case modeRestorePrompt:
+		builder.WriteString(renderRestorePrompt(m))
+	case modeRestoreConfirm:
+		builder.WriteString(renderRestoreConfirm(m))
+	case modeClusterInfo:
+		builder.WriteString(titleStyle.Render("Cluster"))
+		builder.WriteRune('\n')
+		builder.WriteString(m.detailViewport.View())
+	case modeAliases:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("Selected index: %s", emptyIndexPlaceholder(m.aliasIndex))))
+		builder.WriteRune('\n')
+		builder.WriteString(m.aliasList.View())
+	case modeAliasPrompt:
+		if m.aliasRemove {
+			builder.WriteString(titleStyle.Render(fmt.Sprintf("Remove %s from alias", m.aliasIndex)))
+		} else {
+			builder.WriteString(titleStyle.Render(fmt.Sprintf("Add %s to alias", m.aliasIndex)))
+		}
+		builder.WriteRune('\n')
+		builder.WriteString(m.aliasInput.View())
+	case modeTable:
+		builder.WriteString(titleStyle.Render(docsTitle(m)))
+		builder.WriteRune('\n')
+		builder.WriteString(renderTable(m.docList.Items(), m.tableColumns, m.pinnedFields, m.docList.Index(), m.width, m.height-3))
+	case modeSearchFields:
+		builder.WriteString(titleStyle.Render("Fields to search"))
+		builder.WriteRune('\n')
+		builder.WriteString("Comma-separated fields, wildcards and boosts allowed (blank => all fields):\n")
+		builder.WriteString(m.fieldsInput.View())
+		if fieldsLine := renderFieldList(m.availableFields, m.fieldInfo); fieldsLine != "" {
+			builder.WriteRune('\n')
+			builder.WriteString(fieldsLine)
+		}
+	case modeTableColumns:
+		builder.WriteString(titleStyle.Render("Table columns"))
+		builder.WriteRune('\n')
+		builder.WriteString("Comma-separated fields to show next to _id:\n")
+		builder.WriteString(m.columnInput.View())
+		if distinct := m.renderCardinalities(parseFieldList(m.columnInput.Value())); distinct != "" {
+			builder.WriteRune('\n')
+			builder.WriteString(distinct)
+		}
+		if fieldsLine := renderFieldList(m.availableFields, m.fieldInfo); fieldsLine != "" {
+			builder.WriteRune('\n')
+			builder.WriteString(fieldsLine)
+		}
+	case modeProfile:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("Profile: %s | query=%s", m.currentIndex, emptyPlaceholder(m.currentQuery))))
+		builder.WriteRune('\n')
+		builder.WriteString(m.detailViewport.View())
+	case modeMGet:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("Fetch documents from %s by id", m.currentIndex)))
+		builder.WriteRune('\n')
+		builder.WriteString("Document ids (comma or newline separated):\n")
+		builder.WriteString(m.mgetInput.View())
+	case modeImport:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("Import NDJSON into %s", m.currentIndex)))
+		builder.WriteRune('\n')
+		builder.WriteString(m.importInput.View())
+		builder.WriteRune('\n')
+		if m.importContinue {
+			builder.WriteString("On item errors: continue with the next batch")
+		} else {
+			builder.WriteString("On item errors: stop after the failing batch")
+		}
+	case modeExport:
+		builder.WriteString(titleStyle.Render(fmt.Sprintf("Export %s | query=%s", m.currentIndex, emptyPlaceholder(m.currentQuery))))
+		builder.WriteRune('\n')
+		builder.WriteString(m.exportInput.View())
+		builder.WriteRune('\n')
+		builder.WriteString("Writes the _source of every matching document, one per line")
+	case modeAuth:
+		builder.WriteString(titleStyle.Render("Credentials"))
+		builder.WriteRune('\n')
+		builder.WriteString("Enter a username/password, API key or service token to reconnect:\n")
+		for _, input := range m.authInputs {
+			builder.WriteString(input.View())
+			builder.WriteRune('\n')
+		}
+	}
+
+	builder.WriteRune('\n')
+	builder.WriteString(renderStatus(m))
+	return m.layout.frame(builder.String())
+}
+
+func indicesTitle(m model) string {
+	title := "Indices | elastui " + version
+	if m.serverVersion != "" {
+		title += " ↔ ES " + m.serverVersion
+		if VersionMismatch(m.serverVersion) != "" {
+			title += " (version mismatch)"
+		}
+	}
+	if m.client.ReadOnly() {
+		title += " " + readOnlyBadge
+	}
+	return title
+}
+
+func docsTitle(m model) string {
+	title := fmt.Sprintf("Index: %s | query=%s", m.currentIndex, emptyPlaceholder(m.currentQuery))
+	switch m.queryLanguage {
+	case QueryKQL:
+		title += " (KQL)"
+	case QuerySimple:
+		title += fmt.Sprintf(" (simple in %s)", searchFieldsLabel(m.searchFields))
+	}
+	if m.client.ReadOnly() {
+		title += " " + readOnlyBadge
+	}
+	return title
+}
+
+func renderStatus(m model) string {
+	help := "q:quit r:refresh enter:open /:query n:new doc x:delete"
+	if actions := m.actions(); len(actions) > 0 {
+		help = helpLine(actions) + " ctrl+p:commands"
+	}
+	switch m.mode {
+	case modeQuery:
+		if m.liveSearch {
+			help = "enter:run esc:cancel ctrl+l:live search (on)"
+		} else {
+			help = "enter:run esc:cancel ctrl+l:live search (off)"
+		}
+		help += fmt.Sprintf(" ctrl+t:language (%s)", m.queryLanguage)
+		if m.queryLanguage == QuerySimple {
+			help += " tab:fields"
+		}
+	case modeCreateDoc:
+		switch m.createStep {
+		case 0, 1:
+			help = "enter:next esc:cancel"
+		case 2:
+			help = "enter:next tab:toggle op_type esc:cancel"
+		default:
+			help = "enter:create esc:cancel"
+		}
+	case modeConfirmDelete:
+		help = "y:confirm n:cancel"
+	case modeJumpField:
+		help = "enter:jump tab:complete esc:cancel"
+	case modeAuth:
+		help = "tab:next field enter:connect esc:cancel"
+	case modeRestorePrompt:
+		help = "tab:next field enter:continue esc:cancel"
+	case modeRestoreConfirm:
+		help = "enter:restore ctrl+o:toggle override esc:back"
+	case modeAliasPrompt:
+		help = "enter:apply esc:cancel"
+	case modeMGet:
+		help = "ctrl+s:fetch esc:cancel"
+	case modeTableColumns:
+		help = "enter:apply esc:cancel"
+	case modeImport:
+		help = "enter:import tab:toggle stop/continue on error esc:cancel"
+	case modeExport:
+		help = "enter:export esc:cancel"
+	case modePalette:
+		help = "type to filter enter:run esc:close"
+	case modePinFields:
+		help = "enter:apply (comma-separated, empty for server order) esc:cancel"
+	}
+
+	var parts []string
+	if m.inFlight > 0 {
+		status := m.spinner.View()
+		if m.statusMessage != "" {
+			status += " " + statusStyle.Render(m.statusMessage)
+		}
+		if len(m.cancels) > 0 {
+			status += " (esc to cancel)"
+		}
+		parts = append(parts, status)
+	} else if m.statusMessage != "" {
+		parts = append(parts, statusStyle.Render(m.statusMessage))
 	}
 	if m.errMessage != "" {
-		parts = append(parts, errorStyle.Render(m.errMessage))
+		parts = append(parts, errorStyle.Render(truncateString(m.errMessage, 120)))
+		for _, a := range m.errorActions() {
+			parts = append(parts, a.help())
+		}
+	}
+	parts = append(parts, help)
+	return strings.Join(parts, " | ")
+}
+
+func emptyPlaceholder(v string) string {
+	if strings.TrimSpace(v) == "" {
+		return "match_all"
+	}
+	return v
+}
+
+// sortIndexItems returns a sorted copy of items with their pin marks set.
+// Pinned indices come first, in pin order; indices without a creation date
+// sort after dated ones in both age orders.
+func sortIndexItems(items []list.Item, order indexSort, pins indexPins) []list.Item {
+	sorted := slices.Clone(items)
+	for i, item := range sorted {
+		if idx, ok := item.(indexItem); ok {
+			idx.pinned = slices.Contains(pins, idx.info.Name)
+			sorted[i] = idx
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, okA := sorted[i].(indexItem)
+		b, okB := sorted[j].(indexItem)
+		if !okA || !okB {
+			return false
+		}
+		if ra, rb := pins.rank(a.info.Name), pins.rank(b.info.Name); ra != rb {
+			return ra < rb
+		}
+		if order != sortByName && a.info.Created.IsZero() != b.info.Created.IsZero() {
+			return !a.info.Created.IsZero()
+		}
+		switch order {
+		case sortNewest:
+			if !a.info.Created.Equal(b.info.Created) {
+				return a.info.Created.After(b.info.Created)
+			}
+		case sortOldest:
+			if !a.info.Created.Equal(b.info.Created) {
+				return a.info.Created.Before(b.info.Created)
+			}
+		}
+		return a.info.Name < b.info.Name
+	})
+	return sorted
+}
+
+// humanAge renders the time since t as a single coarse unit, e.g. 3d or 5h.
+func humanAge(t time.Time) string {
+	if t.IsZero() {
+		return "n/a"
+	}
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "<1m"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	case age < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	case age < 365*24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	default:
+		return fmt.Sprintf("%.1fy", age.Hours()/24/365)
+	}
+}
+
+func loadIndicesCmd(client *Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		indices, err := client.ListIndices(ctx)
+		if err != nil {
+			return indicesLoadedMsg{err: err}
+		}
+		items := make([]list.Item, 0, len(indices))
+		for _, info := range indices {
+			items = append(items, indexItem{info: info})
+		}
+		return indicesLoadedMsg{items: items}
+	}
+}
+
+func serverVersionCmd(client *Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		version, err := client.ServerVersion(ctx)
+		return serverVersionMsg{version: version, err: err}
+	}
+}
+
+func loadClusterInfoCmd(client *Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		info, err := client.ClusterInfo(ctx)
+		return clusterInfoLoadedMsg{info: info, err: err}
+	}
+}
+
+func loadReposCmd(client *Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		repos, err := client.ListRepositories(ctx)
+		if err != nil {
+			return reposLoadedMsg{err: err}
+		}
+		items := make([]list.Item, 0, len(repos))
+		for _, repo := range repos {
+			items = append(items, repoItem(repo))
+		}
+		return reposLoadedMsg{items: items}
+	}
+}
+
+func loadSnapshotsCmd(client *Client, repo string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		snapshots, err := client.ListSnapshots(ctx, repo)
+		if err != nil {
+			return snapshotsLoadedMsg{repo: repo, err: err}
+		}
+		items := make([]list.Item, 0, len(snapshots))
+		for _, snap := range snapshots {
+			items = append(items, snapshotItem{info: snap})
+		}
+		return snapshotsLoadedMsg{repo: repo, items: items}
+	}
+}
+
+func loadAliasesCmd(client *Client) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		aliases, err := client.ListAliases(ctx)
+		if err != nil {
+			return aliasesLoadedMsg{err: err}
+		}
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		items := make([]list.Item, 0, len(names))
+		for _, name := range names {
+			items = append(items, aliasItem{name: name, indices: aliases[name]})
+		}
+		return aliasesLoadedMsg{items: items}
+	}
+}
+
+func updateAliasesCmd(client *Client, action AliasAction) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		err := client.UpdateAliases(ctx, []AliasAction{action})
+		return aliasesUpdatedMsg{action: action, err: err}
+	}
+}
+
+func loadDocsCmd(parent context.Context, client *Client, index string, query Query, size, seq int, order fieldOrder) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContextFrom(parent)
+		defer cancel()
+		res, err := client.Search(ctx, index, query, size)
+		if err != nil {
+			return docsLoadedMsg{seq: seq, index: index, query: query, err: err}
+		}
+		return newDocsLoadedMsg(seq, index, query, res, order)
+	}
+}
+
+func newDocsLoadedMsg(seq int, index string, query Query, res *SearchResult, order fieldOrder) docsLoadedMsg {
+	items := make([]list.Item, 0, len(res.Documents))
+	fieldSet := make(map[string]struct{})
+	var matched []string
+	for _, doc := range res.Documents {
+		items = append(items, newDocItem(doc, order))
+		collectFields(doc.Source, "", fieldSet)
+		for _, field := range doc.Matched {
+			if !slices.Contains(matched, field) {
+				matched = append(matched, field)
+			}
+		}
+	}
+	fields := make([]string, 0, len(fieldSet))
+	for field := range fieldSet {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	sort.Strings(matched)
+	return docsLoadedMsg{seq: seq, index: index, query: query, took: res.Took, items: items, fields: fields, matched: matched}
+}
+
+func newDocItem(doc Document, order fieldOrder) docItem {
+	item := docItem{id: doc.ID, routing: doc.Routing, source: doc.Source, raw: doc.Raw, partial: doc.Partial}
+	if len(doc.Raw) > 0 {
+		if value, err := decodeOrderedJSON(doc.Raw); err == nil {
+			item.ordered, _ = value.(orderedObject)
+		}
+	}
+	item = item.rendered(order)
+	if len(doc.Source) > 0 {
+		if raw, err := json.Marshal(doc.Source); err == nil {
+			item.size = len(raw)
+		}
+	}
+	return item
+}
+
+// body is the source the document view renders: in the server's key order
+// when it is known.
+func (d docItem) body() any {
+	if len(d.ordered) > 0 {
+		return d.ordered
+	}
+	return d.source
+}
+
+// rendered recomputes the previews and full render cached from the source.
+func (d docItem) rendered(order fieldOrder) docItem {
+	d.preview = previewCompactJSON(d.source, 160, order)
+	d.full = formatFullJSON(d.body(), order)
+	d.text = previewCompactJSON(d.source, 0, order)
+	return d
+}
+
+func profileCmd(parent context.Context, client *Client, index string, query Query) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContextFrom(parent)
+		defer cancel()
+		res, raw, err := client.SearchProfiled(ctx, index, query, docPageSize)
+		if err != nil {
+			return profileLoadedMsg{index: index, query: query.Text, err: err}
+		}
+		var profile map[string]any
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &profile); err != nil {
+				return profileLoadedMsg{index: index, query: query.Text, err: fmt.Errorf("decode profile: %w", err)}
+			}
+		}
+		return profileLoadedMsg{index: index, query: query.Text, took: res.Took, hits: len(res.Documents), profile: profile, rawProfile: raw}
+	}
+}
+
+type profileEntry struct {
+	shard       string
+	kind        string
+	description string
+	nanos       int64
+}
+
+const maxProfileEntries = 10
+
+func renderProfile(msg profileLoadedMsg) string {
+	if len(msg.profile) == 0 {
+		return "(no profile returned)"
+	}
+
+	var entries []profileEntry
+	shardTotals := make(map[string]int64)
+	shards, _ := msg.profile["shards"].([]any)
+	for _, rawShard := range shards {
+		shard, ok := rawShard.(map[string]any)
+		if !ok {
+			continue
+		}
+		shardID, _ := shard["id"].(string)
+		searches, _ := shard["searches"].([]any)
+		for _, rawSearch := range searches {
+			search, ok := rawSearch.(map[string]any)
+			if !ok {
+				continue
+			}
+			queries, _ := search["query"].([]any)
+			for _, rawQuery := range queries {
+				node, ok := rawQuery.(map[string]any)
+				if !ok {
+					continue
+				}
+				shardTotals[shardID] += profileNanos(node)
+				entries = collectProfileEntries(shardID, node, entries)
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].nanos > entries[j].nanos })
+
+	var builder strings.Builder
+	builder.WriteString(titleStyle.Render("Slowest query components"))
+	builder.WriteRune('\n')
+	for i, entry := range entries {
+		if i == maxProfileEntries {
+			break
+		}
+		fmt.Fprintf(&builder, "%10s  %s  %s  %s\n",
+			time.Duration(entry.nanos),
+			entry.kind,
+			truncateString(entry.description, 80),
+			statusStyle.Render(entry.shard),
+		)
+	}
+	if len(entries) == 0 {
+		builder.WriteString("(no query components)\n")
+	}
+
+	shardIDs := make([]string, 0, len(shardTotals))
+	for id := range shardTotals {
+		shardIDs = append(shardIDs, id)
+	}
+	sort.Slice(shardIDs, func(i, j int) bool { return shardTotals[shardIDs[i]] > shardTotals[shardIDs[j]] })
+	builder.WriteRune('\n')
+	builder.WriteString(titleStyle.Render("Query time per shard"))
+	builder.WriteRune('\n')
+	for _, id := range shardIDs {
+		fmt.Fprintf(&builder, "%10s  %s\n", time.Duration(shardTotals[id]), id)
+	}
+
+	builder.WriteRune('\n')
+	builder.WriteString(titleStyle.Render("Raw profile"))
+	builder.WriteRune('\n')
+	if formatted, err := formatOrderedJSON(msg.rawProfile); err == nil {
+		builder.WriteString(formatted)
+	} else {
+		builder.WriteString(formatFullJSON(msg.profile, nil))
 	}
-	parts = append(parts, help)
-	return strings.Join(parts, " | ")
+	return builder.String()
 }
 
-func emptyPlaceholder(v string) string {
-	if strings.TrimSpace(v) == "" {
-		return "match_all"
+func collectProfileEntries(shard string, node map[string]any, out []profileEntry) []profileEntry {
+	kind, _ := node["type"].(string)
+	description, _ := node["description"].(string)
+	out = append(out, profileEntry{shard: shard, kind: kind, description: description, nanos: profileNanos(node)})
+	children, _ := node["children"].([]any)
+	for _, rawChild := range children {
+		if child, ok := rawChild.(map[string]any); ok {
+			out = collectProfileEntries(shard, child, out)
+		}
 	}
-	return v
+	return out
 }
 
-func loadIndicesCmd(client *Client) tea.Cmd {
+func profileNanos(node map[string]any) int64 {
+	nanos, _ := node["time_in_nanos"].(float64)
+	return int64(nanos)
+}
+
+func mgetCmd(client *Client, index string, ids []string, order fieldOrder) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := client.requestContext()
 		defer cancel()
-		indices, err := client.ListIndices(ctx)
+		docs, err := client.MGet(ctx, index, ids, "")
 		if err != nil {
-			return indicesLoadedMsg{err: err}
+			return mgetLoadedMsg{index: index, ids: ids, err: err}
 		}
-		items := make([]list.Item, 0, len(indices))
-		for _, info := range indices {
-			items = append(items, indexItem{info: info})
+		items := make([]list.Item, 0, len(docs))
+		for _, doc := range docs {
+			items = append(items, newDocItem(doc, order))
 		}
-		return indicesLoadedMsg{items: items}
+		return mgetLoadedMsg{index: index, ids: ids, items: items}
 	}
 }
 
-func loadDocsCmd(client *Client, index, query string) tea.Cmd {
+func loadFieldsCmd(client *Client, index string) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := client.requestContext()
 		defer cancel()
-		res, err := client.Search(ctx, index, query, docPageSize)
+		info, err := client.ListFields(ctx, index)
 		if err != nil {
-			return docsLoadedMsg{index: index, query: query, err: err}
+			return fieldsLoadedMsg{index: index, err: err}
 		}
-		items := make([]list.Item, 0, len(res.Documents))
-		fieldSet := make(map[string]struct{})
-		for _, doc := range res.Documents {
-			full := formatFullJSON(doc.Source)
-			preview := previewCompactJSON(doc.Source, 160)
-			items = append(items, docItem{id: doc.ID, preview: preview, full: full})
-			collectFields(doc.Source, "", fieldSet)
+		fields := make([]string, 0, len(info))
+		for _, field := range info {
+			fields = append(fields, field.Name)
 		}
-		fields := make([]string, 0, len(fieldSet))
-		for field := range fieldSet {
-			fields = append(fields, field)
-		}
-		sort.Strings(fields)
-		return docsLoadedMsg{index: index, query: query, took: res.Took, items: items, fields: fields}
+		return fieldsLoadedMsg{index: index, fields: fields, info: info}
 	}
 }
 
-func loadFieldsCmd(client *Client, index string) tea.Cmd {
-	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-		fields, err := client.ListFields(ctx, index)
-		if err != nil {
-			return fieldsLoadedMsg{err: err}
+// formatFullJSON renders a document source, given as a map or an
+// orderedObject, with the pinned keys of order first.
+func formatFullJSON(data any, order fieldOrder) string {
+	if isEmptyObject(data) {
+		return "(no _source)"
+	}
+	var builder strings.Builder
+	renderJSON(&builder, data, 0, "", order, nil)
+	return builder.String()
+}
+
+// jsonField is one key of an orderedObject.
+type jsonField struct {
+	key   string
+	value any
+}
+
+// orderedObject is a JSON object that keeps the key order it was decoded in.
+type orderedObject []jsonField
+
+// formatOrderedJSON renders raw like formatFullJSON but keeps the server's key
+// order, which matters for payloads such as mappings or error bodies.
+func formatOrderedJSON(raw []byte) (string, error) {
+	value, err := decodeOrderedJSON(raw)
+	if err != nil {
+		return "", err
+	}
+	var builder strings.Builder
+	renderJSONValue(&builder, value, 0)
+	return builder.String(), nil
+}
+
+// decodeOrderedJSON decodes raw with objects as orderedObject and numbers as
+// json.Number.
+func decodeOrderedJSON(raw []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	value, err := decodeOrdered(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after JSON value")
+	}
+	return value, nil
+}
+
+// isEmptyObject reports whether data is a missing or empty JSON object.
+func isEmptyObject(data any) bool {
+	switch v := data.(type) {
+	case map[string]any:
+		return len(v) == 0
+	case orderedObject:
+		return len(v) == 0
+	}
+	return data == nil
+}
+
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return tok, nil
+	}
+	switch delim {
+	case '{':
+		obj := orderedObject{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyTok.(string)
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonField{key: key, value: value})
 		}
-		return fieldsLoadedMsg{fields: fields}
+		_, err = dec.Token()
+		return obj, err
+	case '[':
+		arr := []any{}
+		for dec.More() {
+			value, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+		_, err = dec.Token()
+		return arr, err
 	}
+	return nil, fmt.Errorf("unexpected delimiter %s", delim)
 }
 
-func formatFullJSON(data map[string]any) string {
-	if len(data) == 0 {
+// formatAnnotatedJSON is formatFullJSON with annotate's suffixes after values.
+func formatAnnotatedJSON(data any, order fieldOrder, annotate dateAnnotator) string {
+	if isEmptyObject(data) {
 		return "(no _source)"
 	}
 	var builder strings.Builder
-	renderJSONValue(&builder, data, 0)
+	renderJSON(&builder, data, 0, "", order, annotate)
 	return builder.String()
 }
 
 func renderJSONValue(builder *strings.Builder, value any, indent int) {
+	renderJSON(builder, value, indent, "", nil, nil)
+}
+
+func renderJSON(builder *strings.Builder, value any, indent int, path string, order fieldOrder, annotate dateAnnotator) {
 	switch v := value.(type) {
 	case map[string]any:
-		if len(v) == 0 {
-			builder.WriteString("{}")
-			return
-		}
 		keys := make([]string, 0, len(v))
 		for k := range v {
 			keys = append(keys, k)
 		}
-		sort.Strings(keys)
+		order.sortKeys(keys)
+		obj := make(orderedObject, 0, len(keys))
+		for _, key := range keys {
+			obj = append(obj, jsonField{key: key, value: v[key]})
+		}
+		renderJSON(builder, obj, indent, path, order, annotate)
+	case orderedObject:
+		if len(v) == 0 {
+			builder.WriteString("{}")
+			return
+		}
+		v = order.arrange(v)
 		builder.WriteString("{\n")
-		for i, key := range keys {
+		for i, field := range v {
 			builder.WriteString(strings.Repeat("  ", indent+1))
-			builder.WriteString(jsonKeyStyle.Render(fmt.Sprintf("\"%s\"", escapeJSONString(key))))
+			builder.WriteString(jsonKeyStyle.Render(fmt.Sprintf("\"%s\"", escapeJSONString(field.key))))
 			builder.WriteString(": ")
-			renderJSONValue(builder, v[key], indent+1)
-			if i < len(keys)-1 {
+			fieldPath := field.key
+			if path != "" {
+				fieldPath = path + "." + field.key
+			}
+			renderJSON(builder, field.value, indent+1, fieldPath, order, annotate)
+			if i < len(v)-1 {
 				builder.WriteString(",")
 			}
 			builder.WriteString("\n")
@@ -636,7 +3136,7 @@ func renderJSONValue(builder *strings.Builder, value any, indent int) {
 		builder.WriteString("[\n")
 		for i, item := range v {
 			builder.WriteString(strings.Repeat("  ", indent+1))
-			renderJSONValue(builder, item, indent+1)
+			renderJSON(builder, item, indent+1, path, order, annotate)
 			if i < len(v)-1 {
 				builder.WriteString(",")
 			}
@@ -647,6 +3147,8 @@ func renderJSONValue(builder *strings.Builder, value any, indent int) {
 		builder.WriteString(jsonStringStyle.Render(fmt.Sprintf("\"%s\"", escapeJSONString(v))))
 	case float64:
 		builder.WriteString(jsonNumberStyle.Render(strconv.FormatFloat(v, 'f', -1, 64)))
+	case json.Number:
+		builder.WriteString(jsonNumberStyle.Render(v.String()))
 	case int, int64, int32:
 		builder.WriteString(jsonNumberStyle.Render(fmt.Sprintf("%v", v)))
 	case bool:
@@ -656,6 +3158,78 @@ func renderJSONValue(builder *strings.Builder, value any, indent int) {
 	default:
 		builder.WriteString(jsonStringStyle.Render(fmt.Sprintf("\"%v\"", v)))
 	}
+	if annotate != nil {
+		if note := annotate(path, value); note != "" {
+			builder.WriteString(statusStyle.Render("  (" + note + ")"))
+		}
+	}
+}
+
+// jsonLinePaths maps each field path in data to the line formatFullJSON puts
+// it on. Array elements use numeric segments, e.g. items.0.name.
+func jsonLinePaths(data any, order fieldOrder) map[string]int {
+	paths := make(map[string]int)
+	if isEmptyObject(data) {
+		return paths
+	}
+	line := 0
+	collectJSONLines(data, "", &line, paths, order)
+	return paths
+}
+
+func collectJSONLines(value any, prefix string, line *int, paths map[string]int, order fieldOrder) {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			return
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		order.sortKeys(keys)
+		obj := make(orderedObject, 0, len(keys))
+		for _, key := range keys {
+			obj = append(obj, jsonField{key: key, value: v[key]})
+		}
+		collectJSONLines(obj, prefix, line, paths, order)
+	case orderedObject:
+		if len(v) == 0 {
+			return
+		}
+		for _, field := range order.arrange(v) {
+			*line++
+			path := field.key
+			if prefix != "" {
+				path = prefix + "." + field.key
+			}
+			paths[path] = *line
+			collectJSONLines(field.value, path, line, paths, order)
+		}
+		*line++
+	case []any:
+		if len(v) == 0 {
+			return
+		}
+		for i, item := range v {
+			*line++
+			path := strconv.Itoa(i)
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+			paths[path] = *line
+			collectJSONLines(item, path, line, paths, order)
+		}
+		*line++
+	}
+}
+
+// normalizeFieldPath turns items[0].name into items.0.name.
+func normalizeFieldPath(path string) string {
+	path = strings.TrimSpace(path)
+	path = strings.ReplaceAll(path, "[", ".")
+	path = strings.ReplaceAll(path, "]", "")
+	return strings.Trim(path, ".")
 }
 
 func escapeJSONString(value string) string {
@@ -663,15 +3237,16 @@ func escapeJSONString(value string) string {
 	return quoted[1 : len(quoted)-1]
 }
 
-func previewCompactJSON(data map[string]any, maxLen int) string {
+func previewCompactJSON(data map[string]any, maxLen int, order fieldOrder) string {
 	if len(data) == 0 {
 		return "(no _source)"
 	}
-	raw, err := json.Marshal(data)
-	if err != nil {
-		raw, _ = json.MarshalIndent(data, "", "  ")
+	var builder strings.Builder
+	if err := writeCompactJSON(&builder, data, order); err != nil {
+		raw, _ := json.MarshalIndent(data, "", "  ")
+		return truncateString(string(raw), maxLen)
 	}
-	return truncateString(string(raw), maxLen)
+	return truncateString(builder.String(), maxLen)
 }
 
 func truncateString(value string, maxLen int) string {
@@ -688,6 +3263,34 @@ func truncateString(value string, maxLen int) string {
 	return string(runes[:maxLen-3]) + "..."
 }
 
+func renderLiveResults(items []list.Item, width, maxLines int) string {
+	if len(items) == 0 {
+		return statusStyle.Render("(no matching docs)")
+	}
+	if maxLines < 1 {
+		maxLines = 1
+	}
+	lines := make([]string, 0, maxLines)
+	for _, item := range items {
+		if len(lines) == maxLines {
+			break
+		}
+		doc, ok := item.(docItem)
+		if !ok {
+			continue
+		}
+		lines = append(lines, truncateString(displayDocTitle(doc.id)+"  "+doc.preview, width))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func emptyIndexPlaceholder(v string) string {
+	if strings.TrimSpace(v) == "" {
+		return "none"
+	}
+	return v
+}
+
 func displayDocTitle(id string) string {
 	if strings.TrimSpace(id) == "" {
 		return "<generated id>"
@@ -715,7 +3318,7 @@ func collectFields(data any, prefix string, out map[string]struct{}) {
 	}
 }
 
-func renderFieldList(fields []string) string {
+func renderFieldList(fields []string, info map[string]FieldInfo) string {
 	if len(fields) == 0 {
 		return ""
 	}
@@ -725,7 +3328,14 @@ func renderFieldList(fields []string) string {
 		display = fields[:maxFieldsDisplay]
 		truncated = true
 	}
-	text := "Fields: " + strings.Join(display, ", ")
+	labels := make([]string, 0, len(display))
+	for _, field := range display {
+		if info[field].Runtime {
+			field += " (runtime)"
+		}
+		labels = append(labels, field)
+	}
+	text := "Fields: " + strings.Join(labels, ", ")
 	if truncated {
 		text += fmt.Sprintf(" … (+%d more)", len(fields)-maxFieldsDisplay)
 	}
@@ -757,6 +3367,73 @@ func mergeFields(current, incoming []string) []string {
 	return merged
 }
 
+func healthStyle(health string) lipgloss.Style {
+	switch strings.ToLower(health) {
+	case "green":
+		return greenStyle
+	case "yellow":
+		return yellowStyle
+	case "red":
+		return redStyle
+	default:
+		return statusStyle
+	}
+}
+
+func snapshotStateStyle(state string) lipgloss.Style {
+	switch strings.ToUpper(state) {
+	case "SUCCESS":
+		return greenStyle
+	case "PARTIAL", "IN_PROGRESS":
+		return yellowStyle
+	case "FAILED", "INCOMPATIBLE":
+		return redStyle
+	default:
+		return statusStyle
+	}
+}
+
+func renderClusterInfo(info *ClusterInfo) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "Name:        %s\n", info.Name)
+	fmt.Fprintf(&builder, "Status:      %s\n", healthStyle(info.Status).Render(info.Status))
+	fmt.Fprintf(&builder, "Version:     %s\n", info.Version)
+	fmt.Fprintf(&builder, "Nodes:       %d\n", info.NumberOfNodes)
+	fmt.Fprintf(&builder, "Shards:      %d active, %d unassigned\n", info.ActiveShards, info.Unassigned)
+	builder.WriteRune('\n')
+	for _, node := range info.Nodes {
+		builder.WriteString(titleStyle.Render(node.Name))
+		builder.WriteRune('\n')
+		fmt.Fprintf(&builder, "  heap: %s / %s (%s)\n", humanBytes(node.HeapUsedBytes), humanBytes(node.HeapMaxBytes), percentOf(node.HeapUsedBytes, node.HeapMaxBytes))
+		used := node.DiskTotalBytes - node.DiskFreeBytes
+		fmt.Fprintf(&builder, "  disk: %s / %s (%s)\n", humanBytes(used), humanBytes(node.DiskTotalBytes), percentOf(used, node.DiskTotalBytes))
+	}
+	return builder.String()
+}
+
+func percentOf(part, total int64) string {
+	if total <= 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.0f%%", float64(part)/float64(total)*100)
+}
+
+func groupDigits(value int64) string {
+	digits := strconv.FormatInt(value, 10)
+	sign := ""
+	if value < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var builder strings.Builder
+	for i, r := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteRune(r)
+	}
+	return sign + builder.String()
+}
+
 func humanBytes(value int64) string {
 	if value <= 0 {
 		return "0 B"
@@ -774,33 +3451,237 @@ func humanBytes(value int64) string {
 	return fmt.Sprintf("%.2f %s", val, units[i])
 }
 
-func createDocCmd(client *Client, index, id, body string) tea.Cmd {
+func createDocCmd(client *Client, index, body string, opts CreateDocOptions) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := client.requestContext()
 		defer cancel()
-		newID, err := client.CreateDoc(ctx, index, id, []byte(body))
-		if err == nil {
-			_ = client.Refresh(ctx, index)
+		newID, err := client.CreateDoc(ctx, index, []byte(body), opts)
+		if err != nil {
+			return docCreatedMsg{index: index, err: err}
 		}
-		return docCreatedMsg{id: newID, err: err}
+		raw := json.RawMessage(strings.TrimSpace(body))
+		var source map[string]any
+		_ = json.Unmarshal(raw, &source)
+		if opts.Pipeline != "" {
+			// The pipeline may have rewritten the source; show what was stored.
+			if docs, err := client.MGet(ctx, index, []string{newID}, opts.Routing); err == nil && len(docs) == 1 {
+				source, raw = docs[0].Source, docs[0].Raw
+			}
+		}
+		return docCreatedMsg{index: index, id: newID, routing: opts.Routing, source: source, raw: raw}
+	}
+}
+
+func deleteDocCmd(client *Client, index, id, routing string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		err := client.DeleteDoc(ctx, index, id, routing)
+		return docDeletedMsg{index: index, id: id, routing: routing, err: err}
 	}
 }
 
-func deleteDocCmd(client *Client, index, id string) tea.Cmd {
+func refreshIndexCmd(client *Client, index string, manual bool) tea.Cmd {
 	return func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		err := client.Refresh(ctx, index)
+		return indexRefreshedMsg{index: index, manual: manual, err: err}
+	}
+}
+
+// parseImportLine reads one NDJSON line: either a bare document, indexed
+// under a generated id, or a line written by the export, which wraps the
+// _source with its _id. A document cannot carry a top-level _source field
+// itself, so the two never clash.
+func parseImportLine(line []byte) (BulkDoc, error) {
+	if !json.Valid(line) {
+		return BulkDoc{}, errors.New("invalid JSON")
+	}
+	var envelope struct {
+		ID     string          `json:"_id"`
+		Source json.RawMessage `json:"_source"`
+	}
+	if err := json.Unmarshal(line, &envelope); err != nil || envelope.Source == nil {
+		return BulkDoc{Source: append([]byte(nil), line...)}, nil
+	}
+	return BulkDoc{ID: envelope.ID, Source: append([]byte(nil), envelope.Source...)}, nil
+}
+
+func importBatchCmd(client *Client, job *importJob) tea.Cmd {
+	return func() (msg tea.Msg) {
+		// Whatever ends the job, make what was indexed so far searchable.
+		defer func() {
+			if msg.(importBatchMsg).stops() {
+				ctx, cancel := client.longContext()
+				defer cancel()
+				_ = client.Refresh(ctx, job.index)
+			}
+		}()
+		var docs []BulkDoc
+		var failures []BulkFailure
+		for len(docs) < importBatchSize && job.scanner.Scan() {
+			job.line++
+			line := bytes.TrimSpace(job.scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			doc, err := parseImportLine(line)
+			if err != nil {
+				failures = append(failures, BulkFailure{Reason: fmt.Sprintf("line %d: %v", job.line, err)})
+				continue
+			}
+			docs = append(docs, doc)
+		}
+		if err := job.scanner.Err(); err != nil {
+			return importBatchMsg{job: job, failures: failures, err: err}
+		}
+		done := len(docs) < importBatchSize
+
+		ctx, cancel := client.longContext()
 		defer cancel()
-		err := client.DeleteDoc(ctx, index, id)
-		if err == nil {
-			_ = client.Refresh(ctx, index)
+		res, err := client.BulkIndex(ctx, job.index, docs)
+		if err != nil {
+			return importBatchMsg{job: job, failures: failures, err: err}
 		}
-		return docDeletedMsg{id: id, err: err}
+		failures = append(failures, res.Failures...)
+		return importBatchMsg{job: job, succeeded: res.Succeeded, failures: failures, done: done}
+	}
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+func updateDocCmd(client *Client, index, id, routing string, body []byte) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		err := client.UpdateDoc(ctx, index, id, routing, body)
+		return docUpdatedMsg{index: index, id: id, routing: routing, body: body, err: err}
+	}
+}
+
+func editorCommand() []string {
+	if fields := strings.Fields(os.Getenv("EDITOR")); len(fields) > 0 {
+		return fields
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+func openEditorCmd(doc docItem) tea.Cmd {
+	original, err := editorSeed(doc)
+	if err != nil {
+		return func() tea.Msg { return editorFinishedMsg{id: doc.id, err: err} }
+	}
+
+	file, err := os.CreateTemp("", "elastui-*.json")
+	if err != nil {
+		return func() tea.Msg { return editorFinishedMsg{id: doc.id, err: err} }
+	}
+	path := file.Name()
+	_, err = file.Write(original)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return func() tea.Msg { return editorFinishedMsg{id: doc.id, err: err} }
+	}
+
+	argv := append(editorCommand(), path)
+	cmd := exec.Command(argv[0], argv[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorFinishedMsg{id: doc.id, routing: doc.routing, path: path, original: original, err: err}
+	})
+}
+
+// editorSeed indents the stored _source of doc for editing. Starting from
+// the raw bytes rather than the decoded map keeps integers above 2^53 exact
+// and the keys in their order, so saving an edit changes only what was edited.
+func editorSeed(doc docItem) ([]byte, error) {
+	if len(doc.raw) == 0 {
+		return nil, fmt.Errorf("no stored _source for %s", doc.id)
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, doc.raw, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+func (m model) handleEditorFinished(msg editorFinishedMsg) (tea.Model, tea.Cmd) {
+	if msg.path != "" {
+		defer os.Remove(msg.path)
+	}
+	if msg.err != nil {
+		m.errMessage = fmt.Sprintf("editor: %v", msg.err)
+		return m, nil
+	}
+
+	edited, err := os.ReadFile(msg.path)
+	if err != nil {
+		m.errMessage = fmt.Sprintf("editor: %v", err)
+		return m, nil
+	}
+	if bytes.Equal(bytes.TrimSpace(edited), bytes.TrimSpace(msg.original)) {
+		m.statusMessage = fmt.Sprintf("No changes to %s", msg.id)
+		return m, nil
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(edited, &decoded); err != nil {
+		m.errMessage = fmt.Sprintf("edit aborted, invalid JSON: %v", err)
+		return m, nil
+	}
+
+	m.errMessage = ""
+	m.statusMessage = fmt.Sprintf("Updating %s...", msg.id)
+	op := m.track(updateDocCmd(m.client, m.currentIndex, msg.id, msg.routing, bytes.TrimSpace(edited)))
+	return m, op
+}
+
+// durationFromEnv parses a Go duration ("30s") or a plain number of seconds.
+func durationFromEnv(name string) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(name))
+	if value == "" {
+		return 0, nil
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: invalid duration %q", name, value)
 	}
+	return d, nil
 }
 
 func main() {
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 	showHelp := fs.Bool("help", false, "Show help text")
+	showVersion := fs.Bool("version", false, "Print the elastui version and exit")
+	resume := fs.Bool("resume", false, "Reopen the index and query from the last session")
+	profileName := fs.String("profile", "", "Connection profile from ~/.config/elastui/config.json")
+	timeout := fs.Duration("timeout", 0, "Request timeout, e.g. 30s (default 10s or ELASTICSEARCH_TIMEOUT)")
+	longTimeout := fs.Duration("long-timeout", 0, "Timeout for long-running operations such as bulk imports (default 5m or ELASTICSEARCH_LONG_TIMEOUT)")
+	mappingTTL := fs.Duration("mapping-ttl", 5*time.Minute, "How long to reuse an index mapping before fetching it again; r refreshes it anyway (0 disables the cache)")
+	logPath := fs.String("log", "", "Append request and error logs to this file")
+	columns := fs.String("index-columns", "", "Comma-separated details shown per index: "+strings.Join(indexColumnNames, ", ")+" (default all)")
+	readOnly := fs.Bool("read-only", false, "Browse without writing: disable creating, editing, deleting, importing and other changes")
+	largeDoc := fs.String("large-doc", "100kb", "Flag documents whose _source is at least this size, e.g. 512kb or 2mb (0 disables)")
+	fs.IntVar(&maxContentWidth, "max-width", 0, "Maximum width of the views in columns; wider terminals are centered (0 = full width, otherwise at least 40)")
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
 		fmt.Fprintln(os.Stderr)
@@ -808,6 +3689,15 @@ func main() {
 		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_URL           Default http://localhost:9200")
 		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_USERNAME/PASSWORD for basic auth")
 		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_API_KEY       overrides basic auth when set")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_SERVICE_TOKEN bearer token, overrides API key and basic auth")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_CA_CERT       PEM file with the CA used to verify the cluster")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_INSECURE      true to skip TLS certificate verification")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_PROXY         proxy URL (may embed user:pass), overrides HTTP(S)_PROXY")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_COMPRESS      true to gzip request bodies")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_READONLY      true to disable every write, like -read-only")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_DEFAULT_PIPELINE ingest pipeline prefilled when creating documents")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_TIMEOUT       request timeout (e.g. 30s), overridden by -timeout")
+		fmt.Fprintln(os.Stderr, "  ELASTICSEARCH_LONG_TIMEOUT  long-running operation timeout, overridden by -long-timeout")
 		fmt.Fprintln(os.Stderr)
 		fmt.Fprintln(os.Stderr, "Flags:")
 		fs.PrintDefaults()
@@ -822,15 +3712,127 @@ func main() {
 		fs.Usage()
 		return
 	}
+	if *showVersion {
+		fmt.Printf("elastui %s\n", version)
+		return
+	}
+
+	if err := checkMaxWidth(maxContentWidth); err != nil {
+		log.Fatalf("-max-width: %v", err)
+	}
+	if value := strings.TrimSpace(*largeDoc); value != "0" {
+		if largeDocBytes = parseStoreSize(value); largeDocBytes <= 0 {
+			log.Fatalf("-large-doc: invalid size %q", value)
+		}
+	} else {
+		largeDocBytes = 0
+	}
+
+	var pinned fieldOrder
+	var pinnedIndices indexPins
+	if cfg, err := loadConfig(); err != nil {
+		log.Printf("ignoring config: %v", err)
+	} else {
+		pinned = cfg.PinnedFields
+		if len(cfg.IndexColumns) > 0 {
+			if indexColumns, err = parseIndexColumns(cfg.IndexColumns); err != nil {
+				log.Fatalf("index_columns: %v", err)
+			}
+		}
+	}
+	if pins, err := loadPins(); err != nil {
+		log.Printf("ignoring pinned indices: %v", err)
+	} else {
+		pinnedIndices = pins
+	}
+	if value := strings.TrimSpace(*columns); value != "" {
+		var err error
+		if indexColumns, err = parseIndexColumns(parseFieldList(value)); err != nil {
+			log.Fatalf("-index-columns: %v", err)
+		}
+	}
+
+	var err error
+	opts := ClientOptions{Timeout: *timeout, LongTimeout: *longTimeout, ReadOnly: *readOnly, MappingTTL: *mappingTTL}
+	if name := strings.TrimSpace(*profileName); name != "" {
+		if opts.Profile, err = loadProfile(name); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if opts.Timeout == 0 {
+		if opts.Timeout, err = durationFromEnv("ELASTICSEARCH_TIMEOUT"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if opts.LongTimeout == 0 {
+		if opts.LongTimeout, err = durationFromEnv("ELASTICSEARCH_LONG_TIMEOUT"); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if path := strings.TrimSpace(*logPath); path != "" {
+		logFile, err := os.OpenFile(expandHome(path), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			log.Fatalf("cannot open log file: %v", err)
+		}
+		defer logFile.Close()
+		opts.Logger = log.New(logFile, "", log.LstdFlags|log.Lmicroseconds)
+	}
 
-	client, err := NewClientFromEnv()
+	client, err := NewClientFromEnv(opts)
 	if err != nil {
 		log.Fatalf("cannot init elasticsearch client: %v", err)
 	}
+	log.Printf("connecting to %s (auth: %s)", client.Address(), client.AuthMethod())
+	client.logf("connecting to %s (auth: %s)", client.Address(), client.AuthMethod())
 
-	p := tea.NewProgram(newModel(client), tea.WithAltScreen())
-	if err := p.Start(); err != nil {
+	saved, err := loadSession()
+	if err != nil {
+		log.Printf("ignoring saved session: %v", err)
+	}
+
+	initial := newModel(client, saved, *resume)
+	initial.pinnedFields = pinned
+	initial.pinnedIndices = pinnedIndices
+	p := tea.NewProgram(initial, tea.WithAltScreen())
+	final, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
 		os.Exit(1)
 	}
+
+	if m, ok := final.(model); ok && m.export != nil {
+		// Quitting mid-export keeps what was written so far and releases the
+		// export's point in time.
+		m.export.cancel()
+		pit, err := m.export.close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "export to %s: %v\n", m.export.path, err)
+		}
+		if pit != "" {
+			ctx, cancel := m.client.requestContext()
+			if err := m.client.ClosePIT(ctx, pit); err != nil {
+				m.client.logf("close point in time: %v", err)
+			}
+			cancel()
+		}
+	}
+	if m, ok := final.(model); ok && m.pitID != "" {
+		ctx, cancel := m.client.requestContext()
+		if err := m.client.ClosePIT(ctx, m.pitID); err != nil {
+			m.client.logf("close point in time: %v", err)
+		}
+		cancel()
+	}
+	if m, ok := final.(model); ok && m.currentIndex != "" {
+		state := sessionState{Index: m.currentIndex, Query: m.currentQuery, PageSize: m.pageSize}
+		if m.queryLanguage != QueryLucene {
+			state.Language = m.queryLanguage.String()
+		}
+		if m.queryLanguage == QuerySimple {
+			state.Fields = m.searchFields
+		}
+		if err := saveSession(state); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot save session: %v\n", err)
+		}
+	}
 }
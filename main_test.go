@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runCmd runs cmd and any batch it returns, and passes every resulting
+// message, unwrapped from opDoneMsg, to found.
+func runCmd(cmd tea.Cmd, found func(tea.Msg)) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		for _, c := range msg {
+			runCmd(c, found)
+		}
+	case opDoneMsg:
+		found(msg.msg)
+	default:
+		found(msg)
+	}
+}
+
+func TestEditKeepsLargeIntegers(t *testing.T) {
+	const stored = `{"zone":"eu","id":12345678901234567891,"ts":1700000000123456789,"nested":{"b":1,"a":2}}`
+	var sent string
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sent = string(body)
+		w.Write([]byte(`{"result":"updated"}`))
+	})
+
+	doc := newDocItem(newDocument("1", []byte(stored)), nil)
+	original, err := editorSeed(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(original), "12345678901234567891") || !strings.Contains(string(original), "1700000000123456789") {
+		t.Fatalf("seed lost integer precision:\n%s", original)
+	}
+
+	// The user changes one value; everything else must go back as stored.
+	path := filepath.Join(t.TempDir(), "doc.json")
+	edited := strings.Replace(string(original), `"eu"`, `"us"`, 1)
+	if err := os.WriteFile(path, []byte(edited), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := newModel(client, nil, false)
+	m.currentIndex = "logs"
+	_, cmd := m.handleEditorFinished(editorFinishedMsg{id: "1", path: path, original: original})
+	var updated *docUpdatedMsg
+	runCmd(cmd, func(msg tea.Msg) {
+		if u, ok := msg.(docUpdatedMsg); ok {
+			updated = &u
+		}
+	})
+	if updated == nil || updated.err != nil {
+		t.Fatalf("update = %+v, want success", updated)
+	}
+	want := strings.Replace(stored, `"eu"`, `"us"`, 1)
+	if got := strings.Join(strings.Fields(sent), ""); got != want {
+		t.Errorf("sent %s, want %s", got, want)
+	}
+}
+
+func TestEditorSeedRequiresStoredSource(t *testing.T) {
+	doc := docItem{id: "1", source: map[string]any{"a": 1.0}}
+	if _, err := editorSeed(doc); err == nil {
+		t.Error("want an error without the stored _source")
+	}
+}
+
+func TestErrorDetailsRestoresThePreviousView(t *testing.T) {
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {})
+	m := newModel(client, nil, false)
+	m.detailViewport.Width, m.detailViewport.Height = 40, 2
+	m.mode = modeClusterInfo
+	m.detailViewport.SetContent("one\ntwo\nthree\nfour")
+	m.detailViewport.SetYOffset(2)
+	m.errMessage = "boom"
+	m = m.openErrorDetails()
+
+	for name, key := range map[string]tea.KeyMsg{
+		"esc":   {Type: tea.KeyEsc},
+		"clear": {Type: tea.KeyRunes, Runes: []rune("c")},
+	} {
+		t.Run(name, func(t *testing.T) {
+			next, _ := m.updateErrorDetails(key)
+			got := next.(model)
+			if got.mode != modeClusterInfo {
+				t.Errorf("mode = %v, want cluster info", got.mode)
+			}
+			if view := got.detailViewport.View(); strings.Contains(view, "boom") || !strings.HasPrefix(view, "three") {
+				t.Errorf("viewport shows %q, want the cluster info at its offset", view)
+			}
+		})
+	}
+}
+
+func TestImportRefreshesWhenABatchFails(t *testing.T) {
+	var refreshed bool
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/_refresh") {
+			refreshed = true
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"error":"boom"}`))
+	})
+	job := &importJob{index: "logs", scanner: bufio.NewScanner(strings.NewReader(`{"a":1}` + "\n"))}
+	msg := importBatchCmd(client, job)().(importBatchMsg)
+	if msg.err == nil {
+		t.Fatal("want the bulk error")
+	}
+	if !refreshed {
+		t.Error("import ended without refreshing the index")
+	}
+}
+
+func TestRenderCardinalitiesWarnsOnNearlyUniqueFields(t *testing.T) {
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {})
+	m := newModel(client, nil, false)
+	m.currentIndex = "logs"
+	for field, value := range map[string]int64{"level": 3, "request_id": 250000} {
+		m.cardinality[cardinalityKey{index: "logs", field: field}] = value
+	}
+
+	got := m.renderCardinalities([]string{"level", "request_id"})
+	if !strings.Contains(got, "level ≈3") || !strings.Contains(got, "request_id ≈250,000") {
+		t.Fatalf("estimates missing from %q", got)
+	}
+	_, warning, ok := strings.Cut(got, "\n")
+	if !ok || !strings.Contains(warning, "request_id") || strings.Contains(warning, "level") {
+		t.Errorf("warning = %q, want only request_id", warning)
+	}
+	if got := m.renderCardinalities([]string{"level"}); strings.Contains(got, "\n") {
+		t.Errorf("unexpected warning in %q", got)
+	}
+}
+
+func TestExportLinesImportUnderTheirID(t *testing.T) {
+	var line bytes.Buffer
+	doc := newDocument("a\"1", []byte("{\n  \"n\": 12345678901234567891\n}"))
+	if err := writeExportLine(&line, doc); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"_id":"a\"1","_source":{"n":12345678901234567891}}` + "\n"; line.String() != want {
+		t.Fatalf("line = %q, want %q", line.String(), want)
+	}
+
+	got, err := parseImportLine(bytes.TrimSpace(line.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != `a"1` || string(got.Source) != `{"n":12345678901234567891}` {
+		t.Errorf("imported %+v", got)
+	}
+	if got, _ := parseImportLine([]byte(`{"_id":"x","n":1}`)); got.ID != "" || string(got.Source) != `{"_id":"x","n":1}` {
+		t.Errorf("a bare document was unwrapped: %+v", got)
+	}
+	if _, err := parseImportLine([]byte(`{"n":`)); err == nil {
+		t.Error("want an error for invalid JSON")
+	}
+}
+
+func TestEscStopsARunningExport(t *testing.T) {
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {})
+	m := newModel(client, nil, false)
+	m.mode = modeDocs
+	ctx, cancel := context.WithCancel(context.Background())
+	m.export = &exportJob{ctx: ctx, cancel: cancel}
+
+	next, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if ctx.Err() == nil {
+		t.Error("esc did not cancel the export")
+	}
+	if got := next.(model); got.mode != modeDocs {
+		t.Errorf("mode = %v, want the documents to stay open", got.mode)
+	}
+}
+
+func TestAuthPromptReturnsToItsOrigin(t *testing.T) {
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name  string
+		index string
+		from  mode
+		want  mode
+	}{
+		{"cluster info with an index open", "logs", modeClusterInfo, modeClusterInfo},
+		{"document view", "logs", modeDocDetails, modeDocDetails},
+		{"no index open", "", modeSnapshots, modeIndices},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newModel(client, nil, false)
+			m.currentIndex = tt.index
+			m.mode = tt.from
+			m = m.enterAuth()
+			next, _ := m.updateAuth(tea.KeyMsg{Type: tea.KeyEsc})
+			if got := next.(model).mode; got != tt.want {
+				t.Errorf("mode = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
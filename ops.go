@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// opDoneMsg wraps the message of a tracked command so the model can count it
+// as finished before handling the message itself.
+type opDoneMsg struct {
+	id  int
+	msg tea.Msg
+}
+
+func newSpinner() spinner.Model {
+	s := spinner.New()
+	s.Spinner = spinner.MiniDot
+	s.Style = statusStyle
+	return s
+}
+
+// track marks cmd as in flight; the spinner runs until its message arrives.
+func (m *model) track(cmd tea.Cmd) tea.Cmd {
+	m.opSeq++
+	id := m.opSeq
+	m.inFlight++
+	wrapped := func() tea.Msg {
+		return opDoneMsg{id: id, msg: cmd()}
+	}
+	if m.inFlight == 1 {
+		return tea.Batch(wrapped, m.spinner.Tick)
+	}
+	return wrapped
+}
+
+// trackCancelable is track for commands built around a context, which esc
+// cancels while the command is in flight.
+func (m *model) trackCancelable(build func(ctx context.Context) tea.Cmd) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := m.track(build(ctx))
+	m.cancels[m.opSeq] = cancel
+	return cmd
+}
+
+// cancelOps cancels every cancelable command in flight and reports whether
+// there was one.
+func (m *model) cancelOps() bool {
+	if len(m.cancels) == 0 {
+		return false
+	}
+	for id, cancel := range m.cancels {
+		cancel()
+		delete(m.cancels, id)
+	}
+	return true
+}
+
+func (m model) handleOpDone(msg opDoneMsg) (tea.Model, tea.Cmd) {
+	if m.inFlight > 0 {
+		m.inFlight--
+	}
+	if cancel, ok := m.cancels[msg.id]; ok {
+		cancel()
+		delete(m.cancels, msg.id)
+	}
+	return m.Update(msg.msg)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pitKeepAlive is how long Elasticsearch keeps a point in time open between
+// two "load more" requests.
+const pitKeepAlive = 5 * time.Minute
+
+type pitClosedMsg struct {
+	err error
+}
+
+// loadMore appends the next page of the current query. The first call opens a
+// point in time and reloads what is already shown from it, so every later page
+// continues from one consistent view of the index.
+func (m model) loadMore() (model, tea.Cmd) {
+	if m.pageEnd {
+		m.statusMessage = fmt.Sprintf("%s: all %d docs loaded", m.currentIndex, len(m.docList.Items()))
+		return m, nil
+	}
+	if m.loadingMore {
+		// A second request would fetch the same page again and, on the
+		// first load, open a second point in time.
+		return m, nil
+	}
+	m.loadingMore = true
+	size := m.pageSize
+	if m.pitID == "" {
+		size += len(m.docList.Items())
+	}
+	m.statusMessage = fmt.Sprintf("Loading more from %s...", m.currentIndex)
+	index, pit, query, after, seq := m.currentIndex, m.pitID, m.searchQuery(m.currentQuery), m.pageAfter, m.searchSeq
+	order := slices.Clone(m.pinnedFields)
+	op := m.trackCancelable(func(ctx context.Context) tea.Cmd {
+		return loadMoreCmd(ctx, m.client, index, pit, query, size, after, seq, order)
+	})
+	return m, op
+}
+
+func (m model) handleMoreLoaded(msg docsLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.searchSeq || msg.index != m.currentIndex {
+		if msg.pit != "" && msg.pit != m.pitID {
+			return m, closePITCmd(m.client, msg.pit)
+		}
+		return m, nil
+	}
+	m.loadingMore = false
+	var replaced tea.Cmd
+	if m.pitID != "" && msg.pit != m.pitID {
+		replaced = closePITCmd(m.client, m.pitID)
+	}
+	m.pitID = msg.pit
+	if msg.err != nil {
+		closing := m.resetPaging()
+		m = m.failed(msg.err, retryOp{kind: retryMore, index: msg.index})
+		return m, tea.Batch(replaced, closing)
+	}
+
+	items := msg.items
+	if msg.after != nil {
+		items = append(m.docList.Items(), msg.items...)
+	}
+	m.docList.SetItems(items)
+	m.pageAfter = msg.last
+	m.availableFields = mergeFields(m.availableFields, msg.fields)
+	m.matchedFields = mergeFields(m.matchedFields, msg.matched)
+	m.statusMessage = fmt.Sprintf("%s: %d docs • %s • query=%s", msg.index, len(items), msg.took, emptyPlaceholder(msg.query.Text))
+	if len(msg.items) < msg.size {
+		// Nothing left to page through, so the point in time can go now.
+		closing := m.resetPaging()
+		m.pageEnd = true
+		m.statusMessage += " • end of results"
+		return m, tea.Batch(replaced, closing)
+	}
+	m.statusMessage += " • m: load more"
+	return m, replaced
+}
+
+// resetPaging forgets the paging position and closes the point in time, if
+// one is open.
+func (m *model) resetPaging() tea.Cmd {
+	pit := m.pitID
+	m.pitID = ""
+	m.pageAfter = nil
+	m.pageEnd = false
+	m.loadingMore = false
+	if pit == "" {
+		return nil
+	}
+	return closePITCmd(m.client, pit)
+}
+
+func loadMoreCmd(parent context.Context, client *Client, index, pit string, query Query, size int, after []json.RawMessage, seq int, order fieldOrder) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContextFrom(parent)
+		defer cancel()
+		failed := docsLoadedMsg{seq: seq, index: index, query: query, more: true, pit: pit}
+		if pit == "" {
+			id, err := client.OpenPIT(ctx, index, pitKeepAlive)
+			if err != nil {
+				failed.err = err
+				return failed
+			}
+			pit = id
+			failed.pit = id
+		}
+		res, err := client.SearchAfter(ctx, pit, pitKeepAlive, query, size, after)
+		if err != nil {
+			failed.err = err
+			return failed
+		}
+		msg := newDocsLoadedMsg(seq, index, query, res, order)
+		msg.more = true
+		msg.size = size
+		msg.pit = res.PIT
+		msg.after = after
+		msg.last = res.After
+		return msg
+	}
+}
+
+func closePITCmd(client *Client, id string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		return pitClosedMsg{err: client.ClosePIT(ctx, id)}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLoadMoreIgnoresRepeatsWhileLoading(t *testing.T) {
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {})
+	m := newModel(client, nil, false)
+	m.currentIndex = "logs"
+
+	m, first := m.loadMore()
+	if first == nil || !m.loadingMore {
+		t.Fatal("first m did not start a load")
+	}
+	if _, second := m.loadMore(); second != nil {
+		t.Error("second m started another load while one is in flight")
+	}
+
+	next, _ := m.handleMoreLoaded(docsLoadedMsg{seq: m.searchSeq, index: "logs", pit: "pit-1", more: true, size: m.pageSize})
+	if next.(model).loadingMore {
+		t.Error("loadingMore still set after the page arrived")
+	}
+}
+
+func TestHandleMoreLoadedClosesReplacedPIT(t *testing.T) {
+	var closed []string
+	client := newTestClient(t, "", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && strings.HasSuffix(r.URL.Path, "/_pit") {
+			var body struct {
+				ID string `json:"id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			closed = append(closed, body.ID)
+		}
+		w.Write([]byte(`{"succeeded":true}`))
+	})
+	m := newModel(client, nil, false)
+	m.currentIndex = "logs"
+	m.pitID = "pit-1"
+	m.loadingMore = true
+
+	next, cmd := m.handleMoreLoaded(docsLoadedMsg{seq: m.searchSeq, index: "logs", pit: "pit-2", more: true})
+	runCmd(cmd, func(tea.Msg) {})
+	if got := next.(model).pitID; got != "pit-2" {
+		t.Errorf("pitID = %q, want pit-2", got)
+	}
+	if len(closed) != 1 || closed[0] != "pit-1" {
+		t.Errorf("closed = %v, want [pit-1]", closed)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// action is a key binding of a mode. The same list feeds the help line and
+// the command palette, which runs an action by replaying its key.
+type action struct {
+	key   string // as reported by tea.KeyMsg.String(); empty for help-only entries
+	label string // key shown in the help line, when it differs from key
+	desc  string
+	alt   string // another key with the same effect, not shown
+	// mutates marks actions that write to the cluster; read-only mode hides
+	// and blocks them.
+	mutates bool
+}
+
+func (a action) help() string {
+	label := a.label
+	if label == "" {
+		label = a.key
+	}
+	return label + ":" + a.desc
+}
+
+type paletteItem struct {
+	action action
+}
+
+func (i paletteItem) Title() string { return i.action.desc }
+
+func (i paletteItem) Description() string {
+	if i.action.label != "" {
+		return i.action.label
+	}
+	return i.action.key
+}
+
+func (i paletteItem) FilterValue() string { return i.action.desc + " " + i.action.key }
+
+// actions lists the bindings of the current mode that are available, which
+// excludes writes in read-only mode.
+func (m model) actions() []action {
+	actions := m.bindings()
+	if m.client.ReadOnly() {
+		actions = slices.DeleteFunc(actions, func(a action) bool { return a.mutates })
+	}
+	return actions
+}
+
+// blockedAction returns the write action bound to key when the client is
+// read-only.
+func (m model) blockedAction(key string) (action, bool) {
+	if !m.client.ReadOnly() || m.acceptsText() {
+		return action{}, false
+	}
+	for _, a := range m.bindings() {
+		if a.mutates && (a.key == key || a.alt == key) {
+			return a, true
+		}
+	}
+	return action{}, false
+}
+
+// bindings lists every binding of the current mode. Modes that take text
+// input return nil: their keys go to the input, so they have no palette.
+func (m model) bindings() []action {
+	switch m.mode {
+	case modeIndices:
+		return []action{
+			{key: "enter", desc: "open index"},
+			{key: "l", desc: "aliases"},
+			{key: "s", desc: "snapshots"},
+			{key: "C", desc: "cluster"},
+			{key: "S", desc: "resume session"},
+			{key: "r", desc: "reload"},
+			{key: "f", desc: "refresh index", mutates: true},
+			{key: "o", desc: "sort"},
+			{key: "*", desc: "pin"},
+			{key: "q", desc: "quit"},
+		}
+	case modeDocs:
+		autoRefresh := "auto refresh(off)"
+		if m.autoRefresh {
+			autoRefresh = "auto refresh(on)"
+		}
+		actions := []action{
+			{key: "esc", desc: "back"},
+			{key: "r", desc: "refresh"},
+			{key: "m", desc: "load more"},
+			{key: "/", desc: "query"},
+			{key: ";", desc: "filter page"},
+			{key: "|", desc: "table"},
+			{key: "H", desc: "matched columns"},
+			{key: "y", desc: "copy as curl"},
+			{key: "P", desc: "profile"},
+			{key: "D", desc: "date histogram"},
+			{key: "M", desc: "get by ids"},
+			{key: "n", desc: "new", mutates: true},
+			{key: "I", desc: "import", mutates: true},
+			{key: "E", desc: "export"},
+			{key: "x", desc: "delete", alt: "delete", mutates: true},
+			{key: "enter", desc: "view"},
+			{key: "q", desc: "quit"},
+			{key: "A", desc: autoRefresh, mutates: true},
+		}
+		if m.bulkReport != "" {
+			actions = append(actions, action{key: "F", desc: "import failures"})
+		}
+		return actions
+	case modeDocDetails:
+		dateToggle := "dates(off)"
+		if m.annotateDates {
+			dateToggle = "dates(on)"
+		}
+		return []action{
+			{key: "esc", label: "esc/q", desc: "back"},
+			{label: "arrows/jk", desc: "scroll"},
+			{label: "gg/G", desc: "top/bottom"},
+			{label: "ctrl+d/u", desc: "half page"},
+			{key: ":", desc: "jump to field"},
+			{key: "T", desc: dateToggle},
+			{key: "o", desc: "field order"},
+			{key: "e", desc: "edit in $EDITOR", mutates: true},
+		}
+	case modeCurl:
+		return []action{
+			{key: "y", desc: "copy again"},
+			{key: "esc", label: "esc/q", desc: "back"},
+			{label: "arrows/jk", desc: "scroll"},
+		}
+	case modeRepositories:
+		return []action{
+			{key: "enter", desc: "snapshots"},
+			{key: "r", desc: "refresh"},
+			{key: "esc", desc: "back"},
+		}
+	case modeSnapshots:
+		return []action{
+			{key: "t", desc: "restore", mutates: true},
+			{key: "r", desc: "refresh"},
+			{key: "esc", desc: "back"},
+		}
+	case modeClusterInfo:
+		return []action{
+			{key: "esc", label: "esc/q", desc: "back"},
+			{key: "r", desc: "refresh"},
+			{label: "arrows/jk", desc: "scroll"},
+		}
+	case modeError:
+		return []action{
+			{key: "esc", label: "esc/q", desc: "back"},
+			{key: "c", desc: "clear error"},
+			{label: "arrows/jk", desc: "scroll"},
+		}
+	case modeAliases:
+		return []action{
+			{key: "a", desc: "add index to alias", mutates: true},
+			{key: "d", desc: "remove index from alias", mutates: true},
+			{key: "r", desc: "refresh"},
+			{key: "esc", desc: "back"},
+		}
+	case modeProfile:
+		return []action{
+			{key: "esc", label: "esc/q", desc: "back"},
+			{key: "r", desc: "re-run"},
+			{label: "arrows/jk", desc: "scroll"},
+		}
+	case modeHistogram:
+		return []action{
+			{key: "1", desc: "per minute"},
+			{key: "2", desc: "per hour"},
+			{key: "3", desc: "per day"},
+			{key: "f", desc: "next date field"},
+			{key: "r", desc: "refresh"},
+			{key: "esc", label: "esc/q", desc: "back"},
+			{label: "arrows/jk", desc: "scroll"},
+		}
+	case modeBulkReport:
+		return []action{
+			{key: "esc", label: "esc/q", desc: "back"},
+			{label: "arrows/jk", desc: "scroll"},
+		}
+	case modeTable:
+		return []action{
+			{key: "esc", label: "esc/|", desc: "list view"},
+			{key: "c", desc: "columns"},
+			{key: "enter", desc: "view"},
+			{label: "arrows/jk", desc: "move"},
+		}
+	}
+	return nil
+}
+
+// errorActions are the bindings offered while an error is shown.
+func (m model) errorActions() []action {
+	if m.errMessage == "" || m.mode == modeError {
+		return nil
+	}
+	actions := []action{{key: "!", desc: "error details"}}
+	if m.retry != nil {
+		actions = append(actions, action{key: "R", desc: "retry"})
+	}
+	return actions
+}
+
+func helpLine(actions []action) string {
+	parts := make([]string, 0, len(actions))
+	for _, a := range actions {
+		parts = append(parts, a.help())
+	}
+	return strings.Join(parts, " ")
+}
+
+// opensPalette reports whether key should open the command palette. In the
+// document view ":" jumps to a field, so only ctrl+p works there.
+func (m model) opensPalette(key string) bool {
+	if m.acceptsText() || len(m.actions()) == 0 {
+		return false
+	}
+	return key == "ctrl+p" || (key == ":" && m.mode != modeDocDetails)
+}
+
+func newPaletteList() list.Model {
+	palette := list.New([]list.Item{}, list.NewDefaultDelegate(), 0, 0)
+	palette.Title = "Commands"
+	palette.SetShowStatusBar(false)
+	palette.SetShowHelp(false)
+	palette.KeyMap.GoToStart.SetKeys("home")
+	return palette
+}
+
+func (m model) openPalette() (tea.Model, tea.Cmd) {
+	var items []list.Item
+	for _, a := range append(m.actions(), m.errorActions()...) {
+		if a.key != "" {
+			items = append(items, paletteItem{action: a})
+		}
+	}
+	m.paletteList.ResetFilter()
+	m.paletteList.SetItems(items)
+	m.paletteList.Select(0)
+	m.paletteReturn = m.mode
+	m.mode = modePalette
+	// Start in filter mode so typing narrows the list right away.
+	var cmd tea.Cmd
+	m.paletteList, cmd = m.paletteList.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	return m, cmd
+}
+
+func (m model) updatePalette(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc", "ctrl+p":
+			m.mode = m.paletteReturn
+			return m, nil
+		case "enter":
+			item, ok := m.paletteList.SelectedItem().(paletteItem)
+			if !ok {
+				return m, nil
+			}
+			m.mode = m.paletteReturn
+			return m.Update(keyMsgFor(item.action.key))
+		}
+	}
+	var cmd tea.Cmd
+	m.paletteList, cmd = m.paletteList.Update(msg)
+	return m, cmd
+}
+
+// keyMsgFor builds the key message whose String() is key.
+func keyMsgFor(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+const pinMark = "★"
+
+// indexPins lists the pinned indices in the order they were pinned. They are
+// listed before all other indices, whatever the sort order.
+type indexPins []string
+
+func (p indexPins) rank(name string) int {
+	if i := slices.Index(p, name); i >= 0 {
+		return i
+	}
+	return len(p)
+}
+
+func pinsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pins.json"), nil
+}
+
+// loadPins returns the saved pinned indices, or nil when none are saved.
+func loadPins() (indexPins, error) {
+	path, err := pinsPath()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var pins indexPins
+	if err := json.Unmarshal(raw, &pins); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pins, nil
+}
+
+func savePins(pins indexPins) error {
+	path, err := pinsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
+
+// togglePin pins or unpins the selected index and keeps it selected.
+func (m model) togglePin() model {
+	item, ok := m.indexList.SelectedItem().(indexItem)
+	if !ok {
+		return m
+	}
+	name := item.info.Name
+	if i := slices.Index(m.pinnedIndices, name); i >= 0 {
+		m.pinnedIndices = slices.Delete(slices.Clone(m.pinnedIndices), i, i+1)
+		m.statusMessage = fmt.Sprintf("Unpinned %s", name)
+	} else {
+		m.pinnedIndices = append(slices.Clone(m.pinnedIndices), name)
+		m.statusMessage = fmt.Sprintf("Pinned %s", name)
+	}
+	if err := savePins(m.pinnedIndices); err != nil {
+		m.statusMessage += fmt.Sprintf(" (not saved: %v)", err)
+	}
+
+	items := sortIndexItems(m.indexList.Items(), m.indexSort, m.pinnedIndices)
+	m.indexList.SetItems(items)
+	if i := slices.IndexFunc(items, func(it list.Item) bool {
+		idx, ok := it.(indexItem)
+		return ok && idx.info.Name == name
+	}); i >= 0 {
+		m.indexList.Select(i)
+	}
+	return m
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+func TestSortIndexItemsPutsPinsFirst(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	items := []list.Item{
+		indexItem{info: IndexInfo{Name: "alpha", Created: day(3)}},
+		indexItem{info: IndexInfo{Name: "beta", Created: day(1)}},
+		indexItem{info: IndexInfo{Name: "gamma", Created: day(4)}},
+		indexItem{info: IndexInfo{Name: "delta"}},
+		indexItem{info: IndexInfo{Name: "omega", Created: day(2)}},
+	}
+	pins := indexPins{"omega", "beta"}
+
+	tests := []struct {
+		order indexSort
+		want  []string
+	}{
+		{sortByName, []string{"omega", "beta", "alpha", "delta", "gamma"}},
+		{sortNewest, []string{"omega", "beta", "gamma", "alpha", "delta"}},
+		{sortOldest, []string{"omega", "beta", "alpha", "gamma", "delta"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order.String(), func(t *testing.T) {
+			var got []string
+			for _, item := range sortIndexItems(items, tt.order, pins) {
+				idx := item.(indexItem)
+				got = append(got, idx.info.Name)
+				if idx.pinned != slices.Contains(pins, idx.info.Name) {
+					t.Errorf("%s pinned = %v", idx.info.Name, idx.pinned)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
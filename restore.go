@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	restorePollInterval = 2 * time.Second
+	// restoreMaxEmptyPolls bounds how long a restore may report no shards at
+	// all before it is treated as failed.
+	restoreMaxEmptyPolls = 15
+)
+
+type restoreStartedMsg struct {
+	snapshot string
+	targets  []string
+	err      error
+}
+
+type restorePollMsg struct {
+	snapshot   string
+	targets    []string
+	emptyPolls int
+}
+
+type restoreProgressMsg struct {
+	snapshot   string
+	targets    []string
+	done       int
+	total      int
+	emptyPolls int // consecutive polls that found no shards, this one included
+	err        error
+}
+
+func newRestoreInputs() []textinput.Model {
+	pattern := textinput.New()
+	pattern.Placeholder = "rename_pattern, e.g. (.+)"
+	replacement := textinput.New()
+	replacement.Placeholder = "rename_replacement, e.g. restored-$1"
+	return []textinput.Model{pattern, replacement}
+}
+
+func (m model) startRestore() (model, tea.Cmd) {
+	item, ok := m.snapshotList.SelectedItem().(snapshotItem)
+	if !ok {
+		return m, nil
+	}
+	if item.info.State != "SUCCESS" && item.info.State != "PARTIAL" {
+		m.errMessage = fmt.Sprintf("cannot restore snapshot %s in state %s", item.info.Name, item.info.State)
+		return m, nil
+	}
+	m.restoreSnapshot = item.info
+	for i := range m.restoreInputs {
+		m.restoreInputs[i].SetValue("")
+		m.restoreInputs[i].Blur()
+	}
+	m.restoreFocus = 0
+	m.errMessage = ""
+	m.mode = modeRestorePrompt
+	return m, m.restoreInputs[0].Focus()
+}
+
+func (m model) updateRestorePrompt(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.mode = modeSnapshots
+			m.errMessage = ""
+			m.statusMessage = "Restore cancelled"
+			return m, nil
+		case "tab", "down", "shift+tab", "up":
+			step := 1
+			if keyMsg.String() == "shift+tab" || keyMsg.String() == "up" {
+				step = len(m.restoreInputs) - 1
+			}
+			m.restoreInputs[m.restoreFocus].Blur()
+			m.restoreFocus = (m.restoreFocus + step) % len(m.restoreInputs)
+			return m, m.restoreInputs[m.restoreFocus].Focus()
+		case "enter":
+			pattern := strings.TrimSpace(m.restoreInputs[0].Value())
+			replacement := strings.TrimSpace(m.restoreInputs[1].Value())
+			targets, err := renameTargets(m.restoreSnapshot.Indices, pattern, replacement)
+			if err != nil {
+				m.errMessage = fmt.Sprintf("invalid rename pattern: %v", err)
+				return m, nil
+			}
+			m.restoreTargets = targets
+			m.restoreConflicts = m.openIndicesAmong(targets)
+			m.restoreOverride = false
+			m.restoreInputs[m.restoreFocus].Blur()
+			m.restoreConfirm.SetValue("")
+			m.errMessage = ""
+			m.mode = modeRestoreConfirm
+			return m, m.restoreConfirm.Focus()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.restoreInputs[m.restoreFocus], cmd = m.restoreInputs[m.restoreFocus].Update(msg)
+	return m, cmd
+}
+
+func (m model) updateRestoreConfirm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.restoreConfirm.Blur()
+			m.errMessage = ""
+			m.mode = modeRestorePrompt
+			return m, m.restoreInputs[m.restoreFocus].Focus()
+		case "ctrl+o":
+			if len(m.restoreConflicts) > 0 {
+				m.restoreOverride = !m.restoreOverride
+			}
+			return m, nil
+		case "enter":
+			if m.restoreConfirm.Value() != m.restoreSnapshot.Name {
+				m.errMessage = "type the snapshot name exactly to confirm"
+				return m, nil
+			}
+			if len(m.restoreConflicts) > 0 && !m.restoreOverride {
+				m.errMessage = fmt.Sprintf("%d open indices would be overwritten; press ctrl+o to close them first", len(m.restoreConflicts))
+				return m, nil
+			}
+
+			body := map[string]any{}
+			if pattern := strings.TrimSpace(m.restoreInputs[0].Value()); pattern != "" {
+				body["rename_pattern"] = pattern
+				body["rename_replacement"] = strings.TrimSpace(m.restoreInputs[1].Value())
+			}
+			var raw []byte
+			if len(body) > 0 {
+				raw, _ = json.Marshal(body)
+			}
+			var closeFirst []string
+			if m.restoreOverride {
+				closeFirst = m.restoreConflicts
+			}
+
+			m.restoreConfirm.Blur()
+			m.errMessage = ""
+			m.mode = modeSnapshots
+			m.statusMessage = fmt.Sprintf("Starting restore of %s...", m.restoreSnapshot.Name)
+			op := m.track(restoreSnapshotCmd(m.client, m.currentRepo, m.restoreSnapshot.Name, raw, m.restoreTargets, closeFirst))
+			return m, op
+		}
+	}
+
+	var cmd tea.Cmd
+	m.restoreConfirm, cmd = m.restoreConfirm.Update(msg)
+	return m, cmd
+}
+
+func (m model) handleRestoreStarted(msg restoreStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m.showError(msg.err), nil
+	}
+	m.statusMessage = fmt.Sprintf("Restoring %s into %d indices...", msg.snapshot, len(msg.targets))
+	return m, restorePollCmd(msg.snapshot, msg.targets, 0)
+}
+
+func (m model) handleRestoreProgress(msg restoreProgressMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		return m.showError(fmt.Errorf("restore %s: %w", msg.snapshot, msg.err)), nil
+	}
+	if msg.total > 0 && msg.done == msg.total {
+		m.statusMessage = fmt.Sprintf("Restored %s: %d shards recovered", msg.snapshot, msg.total)
+		op := m.track(loadIndicesCmd(m.client))
+		return m, op
+	}
+	if msg.emptyPolls >= restoreMaxEmptyPolls {
+		return m.showError(fmt.Errorf("restore %s: no shard recovery started for %s after %s",
+			msg.snapshot, strings.Join(msg.targets, ", "), restorePollInterval*restoreMaxEmptyPolls)), nil
+	}
+	m.statusMessage = fmt.Sprintf("Restoring %s: %d/%d shards recovered", msg.snapshot, msg.done, msg.total)
+	return m, restorePollCmd(msg.snapshot, msg.targets, msg.emptyPolls)
+}
+
+// openIndicesAmong returns the names that match an open index in the
+// currently loaded index list.
+func (m model) openIndicesAmong(names []string) []string {
+	var open []string
+	for _, item := range m.indexList.Items() {
+		index, ok := item.(indexItem)
+		if ok && index.info.Status == "open" && slices.Contains(names, index.info.Name) {
+			open = append(open, index.info.Name)
+		}
+	}
+	return open
+}
+
+// javaGroupRef matches a numbered group reference in a Java replacement.
+var javaGroupRef = regexp.MustCompile(`\$(\d+)`)
+
+// renameTargets applies rename_pattern/rename_replacement the way the restore
+// API will, so conflicts can be checked before anything is sent.
+// Elasticsearch follows Java, where $1x is group 1 followed by x; Go would
+// read it as a group named 1x, so numbered groups are braced first.
+func renameTargets(indices []string, pattern, replacement string) ([]string, error) {
+	if pattern == "" {
+		return slices.Clone(indices), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	replacement = javaGroupRef.ReplaceAllString(replacement, "$${$1}")
+	targets := make([]string, 0, len(indices))
+	for _, index := range indices {
+		targets = append(targets, re.ReplaceAllString(index, replacement))
+	}
+	return targets, nil
+}
+
+func restoreSnapshotCmd(client *Client, repo, snapshot string, body []byte, targets, closeFirst []string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.longContext()
+		defer cancel()
+		if len(closeFirst) > 0 {
+			if err := client.CloseIndices(ctx, closeFirst); err != nil {
+				return restoreStartedMsg{snapshot: snapshot, err: err}
+			}
+		}
+		err := client.RestoreSnapshot(ctx, repo, snapshot, body)
+		return restoreStartedMsg{snapshot: snapshot, targets: targets, err: err}
+	}
+}
+
+func restorePollCmd(snapshot string, targets []string, emptyPolls int) tea.Cmd {
+	return tea.Tick(restorePollInterval, func(time.Time) tea.Msg {
+		return restorePollMsg{snapshot: snapshot, targets: targets, emptyPolls: emptyPolls}
+	})
+}
+
+func restoreProgressCmd(client *Client, poll restorePollMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := client.requestContext()
+		defer cancel()
+		done, total, err := client.RecoveryProgress(ctx, poll.targets)
+		emptyPolls := 0
+		if total == 0 {
+			emptyPolls = poll.emptyPolls + 1
+		}
+		return restoreProgressMsg{snapshot: poll.snapshot, targets: poll.targets, done: done, total: total, emptyPolls: emptyPolls, err: err}
+	}
+}
+
+func renderRestorePrompt(m model) string {
+	var builder strings.Builder
+	builder.WriteString(titleStyle.Render(fmt.Sprintf("Restore %s from %s", m.restoreSnapshot.Name, m.currentRepo)))
+	builder.WriteRune('\n')
+	builder.WriteString(fmt.Sprintf("Indices: %s\n", truncateString(strings.Join(m.restoreSnapshot.Indices, ", "), 200)))
+	builder.WriteString("Optional rename (leave blank to restore under the original names):\n")
+	for _, input := range m.restoreInputs {
+		builder.WriteString(input.View())
+		builder.WriteRune('\n')
+	}
+	return builder.String()
+}
+
+func renderRestoreConfirm(m model) string {
+	var builder strings.Builder
+	builder.WriteString(titleStyle.Render(fmt.Sprintf("Confirm restore of %s", m.restoreSnapshot.Name)))
+	builder.WriteRune('\n')
+	builder.WriteString(fmt.Sprintf("Restores %d indices: %s\n", len(m.restoreTargets), truncateString(strings.Join(m.restoreTargets, ", "), 200)))
+	if len(m.restoreConflicts) > 0 {
+		builder.WriteString(errorStyle.Render(fmt.Sprintf("Open indices that would be overwritten: %s", strings.Join(m.restoreConflicts, ", "))))
+		builder.WriteRune('\n')
+		override := "no"
+		if m.restoreOverride {
+			override = "yes, close them first"
+		}
+		builder.WriteString(fmt.Sprintf("Override (ctrl+o): %s\n", override))
+	}
+	builder.WriteString(fmt.Sprintf("Type %q to confirm:\n", m.restoreSnapshot.Name))
+	builder.WriteString(m.restoreConfirm.View())
+	return builder.String()
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRenameTargets(t *testing.T) {
+	indices := []string{"logs-1", "metrics"}
+	tests := []struct {
+		pattern, replacement string
+		want                 []string
+	}{
+		{"", "", []string{"logs-1", "metrics"}},
+		{"(.+)", "restored-$1", []string{"restored-logs-1", "restored-metrics"}},
+		{"(.+)", "$1_copy", []string{"logs-1_copy", "metrics_copy"}},
+		{"(logs)-(\\d)", "$2x$1", []string{"1xlogs", "metrics"}},
+	}
+	for _, tt := range tests {
+		got, err := renameTargets(indices, tt.pattern, tt.replacement)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("renameTargets(%q, %q) = %v, want %v", tt.pattern, tt.replacement, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type retryKind int
+
+const (
+	retryIndices retryKind = iota
+	retryDocs
+	retryFields
+	retryDelete
+	retryRefresh
+	retryAliases
+	retryAliasUpdate
+	retryMGet
+	retryProfile
+	retryRepos
+	retrySnapshots
+	retryClusterInfo
+	retryMore
+	retryHistogram
+)
+
+// retryOp describes a failed request well enough to issue it again. Commands
+// capture the client, so the closure itself is not kept: a retry after
+// re-authenticating must use the new client.
+type retryOp struct {
+	kind    retryKind
+	index   string
+	query   string
+	id      string
+	routing string
+	repo    string
+	ids     []string
+	action  AliasAction
+}
+
+// failed shows err and remembers op so R can retry it.
+func (m model) failed(err error, op retryOp) model {
+	m = m.showError(err)
+	if m.errMessage != "" {
+		m.retry = &op
+	}
+	return m
+}
+
+func (m model) retryLast() (tea.Model, tea.Cmd) {
+	op := *m.retry
+	m.retry = nil
+	m.errMessage = ""
+	m.statusMessage = "Retrying..."
+
+	var cmd tea.Cmd
+	switch op.kind {
+	case retryIndices:
+		cmd = loadIndicesCmd(m.client)
+	case retryDocs:
+		if op.index != m.currentIndex {
+			m.statusMessage = fmt.Sprintf("Not retrying: %s is no longer open", op.index)
+			return m, nil
+		}
+		search := m.searchCmd(op.query)
+		return m, search
+	case retryFields:
+		cmd = loadFieldsCmd(m.client, op.index)
+	case retryDelete:
+		cmd = deleteDocCmd(m.client, op.index, op.id, op.routing)
+	case retryRefresh:
+		cmd = refreshIndexCmd(m.client, op.index, true)
+	case retryAliases:
+		cmd = loadAliasesCmd(m.client)
+	case retryAliasUpdate:
+		cmd = updateAliasesCmd(m.client, op.action)
+	case retryMGet:
+		cmd = mgetCmd(m.client, op.index, op.ids, slices.Clone(m.pinnedFields))
+	case retryProfile:
+		profile := m.trackCancelable(func(ctx context.Context) tea.Cmd {
+			return profileCmd(ctx, m.client, op.index, m.searchQuery(op.query))
+		})
+		return m, profile
+	case retryRepos:
+		cmd = loadReposCmd(m.client)
+	case retrySnapshots:
+		cmd = loadSnapshotsCmd(m.client, op.repo)
+	case retryClusterInfo:
+		cmd = loadClusterInfoCmd(m.client)
+	case retryHistogram:
+		if op.index != m.currentIndex || m.mode != modeHistogram {
+			m.statusMessage = "Not retrying: the histogram is no longer open"
+			return m, nil
+		}
+		return m.reloadHistogram()
+	case retryMore:
+		if op.index != m.currentIndex {
+			m.statusMessage = fmt.Sprintf("Not retrying: %s is no longer open", op.index)
+			return m, nil
+		}
+		return m.loadMore()
+	}
+	tracked := m.track(cmd)
+	return m, tracked
+}
+
+// acceptsText reports whether keys currently go to a text input, in which
+// case single-letter shortcuts such as R must not fire.
+func (m model) acceptsText() bool {
+	switch m.mode {
+	case modeQuery, modeCreateDoc, modeAuth, modeImport, modeExport, modeAliasPrompt, modeMGet,
+		modeTableColumns, modeJumpField, modeRestorePrompt, modeRestoreConfirm, modeConfirmDelete, modePalette,
+		modePinFields, modeSearchFields:
+		return true
+	case modeDocs:
+		return m.docList.FilterState() == list.Filtering
+	}
+	return false
+}
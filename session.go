@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sessionState is the browsing position restored across launches.
+type sessionState struct {
+	Index    string `json:"index"`
+	Query    string `json:"query,omitempty"`
+	PageSize int    `json:"page_size,omitempty"`
+	// Language is "KQL" or "simple"; empty means Lucene.
+	Language string `json:"language,omitempty"`
+	// Fields are the fields of a simple search.
+	Fields []string `json:"fields,omitempty"`
+}
+
+func sessionPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "session.json"), nil
+}
+
+// loadSession returns the saved session, or nil when none exists.
+func loadSession() (*sessionState, error) {
+	path, err := sessionPath()
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state sessionState
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(state.Index) == "" {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func saveSession(state sessionState) error {
+	path, err := sessionPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	raw, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(raw, '\n'), 0o644)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchFieldsLabel describes the fields of a simple search.
+func searchFieldsLabel(fields []string) string {
+	if len(fields) == 0 {
+		return "all fields"
+	}
+	return strings.Join(fields, ", ")
+}
+
+func (m model) openSearchFields() (model, tea.Cmd) {
+	m.queryInput.Blur()
+	m.fieldsInput.SetValue(strings.Join(m.searchFields, ", "))
+	m.fieldsInput.CursorEnd()
+	m.mode = modeSearchFields
+	return m, m.fieldsInput.Focus()
+}
+
+func (m model) updateSearchFields(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.fieldsInput.Blur()
+			m.mode = modeQuery
+			return m, m.queryInput.Focus()
+		case tea.KeyEnter:
+			m.searchFields = parseFieldList(m.fieldsInput.Value())
+			m.fieldsInput.Blur()
+			m.mode = modeQuery
+			m.statusMessage = fmt.Sprintf("Simple search in %s", searchFieldsLabel(m.searchFields))
+			return m, m.queryInput.Focus()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.fieldsInput, cmd = m.fieldsInput.Update(msg)
+	return m, cmd
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	defaultTableColumns = 4
+	maxColumnWidth      = 40
+	minColumnWidth      = 4
+	columnGap           = "  "
+)
+
+func (m model) enterTable() model {
+	// The table renders every loaded doc, so a list filter would desync rows.
+	m.docList.ResetFilter()
+	if len(m.tableColumns) == 0 {
+		m.tableColumns = defaultColumns(m.docList.Items(), defaultTableColumns)
+	}
+	m.mode = modeTable
+	m.statusMessage = fmt.Sprintf("Table view: %s", strings.Join(m.tableColumns, ", "))
+	return m
+}
+
+func (m model) updateTable(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "q", "esc", "|":
+			m.mode = modeDocs
+			m.statusMessage = fmt.Sprintf("Back to %s", m.currentIndex)
+			return m, nil
+		case "up", "k":
+			m.docList.CursorUp()
+			return m, nil
+		case "down", "j":
+			m.docList.CursorDown()
+			return m, nil
+		case "g":
+			if m.takeG() {
+				m.docList.Select(0)
+			}
+			return m, nil
+		case "G":
+			if n := len(m.docList.Items()); n > 0 {
+				m.docList.Select(n - 1)
+			}
+			return m, nil
+		case "c":
+			m.columnInput.SetValue(strings.Join(m.tableColumns, ", "))
+			m.columnInput.CursorEnd()
+			m.columnInput.Focus()
+			m.mode = modeTableColumns
+			op := m.requestCardinalities(m.tableColumns)
+			return m, op
+		case "enter", "v":
+			if doc, ok := m.docList.SelectedItem().(docItem); ok {
+				return m.openDocDetails(doc, modeTable), nil
+			}
+			return m, nil
+		case "!":
+			return m.openErrorDetails(), nil
+		}
+	}
+	return m, nil
+}
+
+func (m model) updateTableColumns(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.Type {
+		case tea.KeyEsc:
+			m.mode = modeTable
+			m.columnInput.Blur()
+			return m, nil
+		case tea.KeyEnter:
+			m.tableColumns = parseFieldList(m.columnInput.Value())
+			m.columnInput.Blur()
+			return m.enterTable(), nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.columnInput, cmd = m.columnInput.Update(msg)
+	op := m.requestCardinalities(parseFieldList(m.columnInput.Value()))
+	return m, tea.Batch(cmd, op)
+}
+
+// defaultColumns picks the first scalar fields present in the loaded documents.
+func defaultColumns(items []list.Item, limit int) []string {
+	seen := make(map[string]struct{})
+	for _, item := range items {
+		doc, ok := item.(docItem)
+		if !ok {
+			continue
+		}
+		collectLeafFields(doc.source, "", seen)
+	}
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	if len(fields) > limit {
+		fields = fields[:limit]
+	}
+	return fields
+}
+
+// matchedColumns turns highlighted field names into table columns. Multi-field
+// subfields such as message.keyword are folded into their parent, whose value
+// is what _source actually holds.
+func matchedColumns(matched []string) []string {
+	var columns []string
+	for _, field := range matched {
+		field = strings.TrimSuffix(field, ".keyword")
+		if !slices.Contains(columns, field) {
+			columns = append(columns, field)
+		}
+	}
+	return columns
+}
+
+func collectLeafFields(data map[string]any, prefix string, out map[string]struct{}) {
+	for key, value := range data {
+		field := key
+		if prefix != "" {
+			field = prefix + "." + key
+		}
+		if child, ok := value.(map[string]any); ok {
+			collectLeafFields(child, field, out)
+			continue
+		}
+		out[field] = struct{}{}
+	}
+}
+
+// lookupField resolves a dotted path in a document source, accepting keys that
+// themselves contain dots.
+func lookupField(data map[string]any, path string) (any, bool) {
+	if value, ok := data[path]; ok {
+		return value, true
+	}
+	for i := strings.Index(path, "."); i >= 0; i = nextDot(path, i) {
+		child, ok := data[path[:i]].(map[string]any)
+		if !ok {
+			continue
+		}
+		if value, ok := lookupField(child, path[i+1:]); ok {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+func nextDot(path string, after int) int {
+	next := strings.Index(path[after+1:], ".")
+	if next < 0 {
+		return -1
+	}
+	return after + 1 + next
+}
+
+func cellValue(source map[string]any, field string, order fieldOrder) string {
+	value, ok := lookupField(source, field)
+	if !ok || value == nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		return previewCompactJSON(v, maxColumnWidth, order)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func renderTable(items []list.Item, columns []string, order fieldOrder, selected, width, height int) string {
+	headers := append([]string{"_id"}, columns...)
+	rows := make([][]string, 0, len(items))
+	for _, item := range items {
+		doc, ok := item.(docItem)
+		if !ok {
+			continue
+		}
+		row := []string{displayDocTitle(doc.id)}
+		for _, column := range columns {
+			row = append(row, strings.ReplaceAll(cellValue(doc.source, column, order), "\n", " "))
+		}
+		rows = append(rows, row)
+	}
+
+	widths := columnWidths(headers, rows, width)
+	var builder strings.Builder
+	builder.WriteString(titleStyle.Render(formatRow(headers, widths)))
+	builder.WriteRune('\n')
+	if len(rows) == 0 {
+		builder.WriteString(statusStyle.Render("(no docs)"))
+		return builder.String()
+	}
+
+	if height < 1 {
+		height = 1
+	}
+	start := 0
+	if selected >= height {
+		start = selected - height + 1
+	}
+	end := min(start+height, len(rows))
+	for i := start; i < end; i++ {
+		line := formatRow(rows[i], widths)
+		if i == selected {
+			line = selectedRowStyle.Render(line)
+		}
+		builder.WriteString(line)
+		if i < end-1 {
+			builder.WriteRune('\n')
+		}
+	}
+	return builder.String()
+}
+
+func columnWidths(headers []string, rows [][]string, total int) []int {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = len([]rune(header))
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len([]rune(cell)))
+		}
+	}
+	sum := 0
+	for i := range widths {
+		widths[i] = max(min(widths[i], maxColumnWidth), minColumnWidth)
+		sum += widths[i]
+	}
+	sum += len(columnGap) * (len(widths) - 1)
+	for sum > total && total > 0 {
+		widest := 0
+		for i := range widths {
+			if widths[i] > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+		widths[widest]--
+		sum--
+	}
+	return widths
+}
+
+func formatRow(cells []string, widths []int) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = truncateString(cell, widths[i])
+		parts[i] = cell + strings.Repeat(" ", widths[i]-len([]rune(cell)))
+	}
+	return strings.Join(parts, columnGap)
+}